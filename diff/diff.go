@@ -0,0 +1,202 @@
+// Package diff computes line-based differences between two texts and
+// renders them as unified diffs.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op says what happened to a line.
+type Op int
+
+const (
+	Equal Op = iota
+	Insert
+	Delete
+)
+
+// Line is one line of a diff.
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Lines diffs a and b line by line, using the longest common subsequence so
+// that the result has as few inserted and deleted lines as possible. Lines
+// the texts start and end with are matched up front, so the quadratic LCS
+// table only covers the part that changed.
+func Lines(a, b string) []Line {
+	as, bs := splitLines(a), splitLines(b)
+
+	pre := 0
+	for pre < len(as) && pre < len(bs) && as[pre] == bs[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(as)-pre && suf < len(bs)-pre && as[len(as)-1-suf] == bs[len(bs)-1-suf] {
+		suf++
+	}
+
+	out := make([]Line, 0, len(as)+len(bs)-pre-suf)
+	for _, l := range as[:pre] {
+		out = append(out, Line{Equal, l})
+	}
+	out = appendLCS(out, as[pre:len(as)-suf], bs[pre:len(bs)-suf])
+	for _, l := range as[len(as)-suf:] {
+		out = append(out, Line{Equal, l})
+	}
+	return out
+}
+
+// appendLCS appends the minimal diff of as and bs to out.
+func appendLCS(out []Line, as, bs []string) []Line {
+	// lcs[i][j] is the LCS length of as[i:] and bs[j:].
+	lcs := make([][]int, len(as)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bs)+1)
+	}
+	for i := len(as) - 1; i >= 0; i-- {
+		for j := len(bs) - 1; j >= 0; j-- {
+			if as[i] == bs[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(as) && j < len(bs) {
+		switch {
+		case as[i] == bs[j]:
+			out = append(out, Line{Equal, as[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, Line{Delete, as[i]})
+			i++
+		default:
+			out = append(out, Line{Insert, bs[j]})
+			j++
+		}
+	}
+	for ; i < len(as); i++ {
+		out = append(out, Line{Delete, as[i]})
+	}
+	for ; j < len(bs); j++ {
+		out = append(out, Line{Insert, bs[j]})
+	}
+	return out
+}
+
+// Unified renders the changes from a to b as a unified diff with context
+// unchanged lines around each change. It returns "" when a and b are equal.
+func Unified(aName, bName, a, b string, context int) string {
+	lines := Lines(a, b)
+
+	var sb strings.Builder
+	for _, h := range hunks(lines, context) {
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- %s\n+++ %s\n", aName, bName)
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(h.aStart, h.aLen), hunkRange(h.bStart, h.bLen))
+		for _, l := range lines[h.from:h.to] {
+			sb.WriteString(prefix(l.Op))
+			sb.WriteString(l.Text)
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
+
+type hunk struct {
+	from, to     int // range in the Lines slice
+	aStart, aLen int // 1-based line range in a
+	bStart, bLen int // 1-based line range in b
+}
+
+// hunks groups changed lines, plus context around them, into hunks. Changes
+// separated by at most 2*context unchanged lines share a hunk.
+func hunks(lines []Line, context int) []hunk {
+	var out []hunk
+	aLine, bLine := 1, 1
+	var cur *hunk
+	lastChange := -1
+
+	for i, l := range lines {
+		if l.Op != Equal {
+			if cur == nil || i-lastChange-1 > 2*context {
+				if cur != nil {
+					out = append(out, *cur)
+				}
+				start := max(i-context, 0)
+				cur = &hunk{from: start}
+				// Walk back to find the line numbers at start.
+				cur.aStart, cur.bStart = aLine, bLine
+				for k := i - 1; k >= start; k-- {
+					if lines[k].Op != Insert {
+						cur.aStart--
+					}
+					if lines[k].Op != Delete {
+						cur.bStart--
+					}
+				}
+			}
+			lastChange = i
+		}
+		if cur != nil {
+			cur.to = min(lastChange+context+1, len(lines))
+		}
+		if l.Op != Insert {
+			aLine++
+		}
+		if l.Op != Delete {
+			bLine++
+		}
+	}
+	if cur != nil {
+		out = append(out, *cur)
+	}
+
+	for k := range out {
+		h := &out[k]
+		for _, l := range lines[h.from:h.to] {
+			if l.Op != Insert {
+				h.aLen++
+			}
+			if l.Op != Delete {
+				h.bLen++
+			}
+		}
+	}
+	return out
+}
+
+func hunkRange(start, length int) string {
+	if length == 0 {
+		// An empty range names the line before it, as diff(1) does.
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if length == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, length)
+}
+
+func prefix(op Op) string {
+	switch op {
+	case Insert:
+		return "+"
+	case Delete:
+		return "-"
+	}
+	return " "
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,107 @@
+package diff_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mtix28/noteme/diff"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "equal",
+			a:    "one\ntwo\n",
+			b:    "one\ntwo\n",
+			want: "",
+		},
+		{
+			name: "changed line",
+			a:    "a\nb\nc\n",
+			b:    "a\nB\nc\n",
+			want: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name: "from empty",
+			a:    "",
+			b:    "hello\n",
+			want: "--- old\n+++ new\n@@ -0,0 +1 @@\n+hello\n",
+		},
+		{
+			name: "two hunks",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			b:    "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			want: "--- old\n+++ new\n" +
+				"@@ -1,2 +1,2 @@\n-1\n+one\n 2\n" +
+				"@@ -9,2 +9,2 @@\n 9\n-10\n+ten\n",
+		},
+		{
+			name: "nearby changes merge",
+			a:    "1\n2\n3\n4\n",
+			b:    "x\n2\n3\ny\n",
+			want: "--- old\n+++ new\n@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n-4\n+y\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			context := 1
+			if got := diff.Unified("old", "new", tt.a, tt.b, context); got != tt.want {
+				t.Fatalf("unexpected diff:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinesIsMinimal(t *testing.T) {
+	lines := diff.Lines("a\nb\nc\nd\n", "a\nc\nd\ne\n")
+	var ops []string
+	for _, l := range lines {
+		ops = append(ops, [...]string{"=", "+", "-"}[l.Op]+l.Text)
+	}
+	if got := strings.Join(ops, " "); got != "=a -b =c =d +e" {
+		t.Fatalf("unexpected diff: %s", got)
+	}
+}
+
+func TestLinesLongTextWithSmallChange(t *testing.T) {
+	var a, b strings.Builder
+	for i := range 50000 {
+		fmt.Fprintf(&a, "line %d\n", i)
+		if i == 25000 {
+			b.WriteString("changed\n")
+		} else {
+			fmt.Fprintf(&b, "line %d\n", i)
+		}
+	}
+	lines := diff.Lines(a.String(), b.String())
+	if len(lines) != 50001 {
+		t.Fatalf("got %d lines, want 50001", len(lines))
+	}
+	var changed []diff.Line
+	for _, l := range lines {
+		if l.Op != diff.Equal {
+			changed = append(changed, l)
+		}
+	}
+	want := []diff.Line{{Op: diff.Delete, Text: "line 25000"}, {Op: diff.Insert, Text: "changed"}}
+	if len(changed) != 2 || changed[0] != want[0] || changed[1] != want[1] {
+		t.Fatalf("changed lines = %v, want %v", changed, want)
+	}
+}
+
+func TestLinesRepeatedLinesAtEdges(t *testing.T) {
+	lines := diff.Lines("a\na\nb\na\n", "a\nb\na\na\n")
+	var ops []string
+	for _, l := range lines {
+		ops = append(ops, [...]string{"=", "+", "-"}[l.Op]+l.Text)
+	}
+	if got := strings.Join(ops, " "); got != "=a -a =b +a =a" {
+		t.Fatalf("unexpected diff: %s", got)
+	}
+}
@@ -1,23 +1,94 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mtix28/noteme/storage"
 	"github.com/mtix28/noteme/ui"
 )
 
 func main() {
-	m, err := ui.NewModel()
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run is main with its arguments and output passed in, returning the exit
+// code so that deferred cleanup runs first.
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("noteme", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	dataDir := flags.String("data-dir", "", "directory to store notes and todos in (overrides $NOTEME_DIR)")
+	backend := flags.String("backend", "json", "storage backend: json, markdown or sqlite")
+	trashDays := flags.Int("trash-days", 30, "days to keep deleted items in the trash (0 keeps them forever)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if !knownBackend(*backend) {
+		fmt.Fprintf(stderr, "unknown backend %q (want json, markdown or sqlite)\n", *backend)
+		return 2
+	}
+
+	dir, err := storage.DataDir(*dataDir)
 	if err != nil {
-		fmt.Printf("Error initializing model: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(stderr, "Error resolving data directory: %v\n", err)
+		return 1
 	}
 
+	search := flags.Arg(0) == "search"
+	var store storage.Backend
+	if search && !storage.HasData(dir) {
+		// Searching is read-only: don't create and seed a fresh directory.
+		store = storage.NewMemoryStorage()
+	} else if store, err = openBackend(*backend, dir); err != nil {
+		fmt.Fprintf(stderr, "Error initializing storage: %v\n", err)
+		return 1
+	}
+	if c, ok := store.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	if search {
+		if err := searchCommand(store, flags.Args()[1:], stdout, stderr); err != nil {
+			fmt.Fprintf(stderr, "noteme search: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	if trash, ok := store.(storage.Trash); ok && *trashDays > 0 {
+		if _, err := trash.PurgeTrash(time.Now().AddDate(0, 0, -*trashDays)); err != nil {
+			fmt.Fprintf(stderr, "Error emptying trash: %v\n", err)
+		}
+	}
+	m := ui.NewModel(store)
+
 	p := tea.NewProgram(m, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
-		fmt.Printf("Error running program: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(stderr, "Error running program: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func knownBackend(name string) bool {
+	switch name {
+	case "json", "markdown", "md", "sqlite":
+		return true
+	}
+	return false
+}
+
+func openBackend(name, dir string) (storage.Backend, error) {
+	switch name {
+	case "json":
+		return storage.NewStorageAt(dir)
+	case "markdown", "md":
+		return storage.NewMarkdownStorageAt(dir)
+	case "sqlite":
+		return storage.NewSQLiteStorageAt(dir)
 	}
+	return nil, fmt.Errorf("unknown backend %q (want json, markdown or sqlite)", name)
 }
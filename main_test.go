@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtix28/noteme/model"
+	"github.com/mtix28/noteme/storage"
+)
+
+func searchDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := storage.NewStorageAt(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+	if err := store.SaveNotes([]model.Note{
+		{ID: "n1", Title: "Budget plan", Content: "numbers for next year", Folder: "work", Tags: []string{"money"}, CreatedAt: created},
+		{ID: "n2", Title: "Shopping", Content: "milk", Folder: "home", CreatedAt: created},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveTodos([]model.Todo{
+		{ID: "t1", Content: "send budget", Done: true, CreatedAt: created},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func runSearch(args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	code = run(args, &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+func TestSearchCommand(t *testing.T) {
+	dir := searchDir(t)
+
+	code, out, stderr := runSearch("-data-dir", dir, "search", "budget")
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr %q", code, stderr)
+	}
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), out)
+	}
+	note, todo := lines[0], lines[1]
+	if strings.HasPrefix(note, "todo") {
+		note, todo = todo, note
+	}
+	for _, want := range []string{"note", "2026-03-02", "Budget plan", "work", "#money"} {
+		if !strings.Contains(note, want) {
+			t.Errorf("note line %q doesn't have %q", note, want)
+		}
+	}
+	if !strings.HasPrefix(todo, "todo") || !strings.Contains(todo, "[x] send budget") {
+		t.Errorf("todo line = %q, want the done todo", todo)
+	}
+
+	code, out, _ = runSearch("-data-dir", dir, "search", "-limit", "1", "budget", "-is:todo")
+	if code != 0 || strings.Count(out, "\n") != 1 || !strings.Contains(out, "Budget plan") {
+		t.Errorf("limited search: exit code %d, output %q", code, out)
+	}
+}
+
+func TestSearchCommandErrors(t *testing.T) {
+	dir := searchDir(t)
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stderr string
+	}{
+		{"empty query", []string{"-data-dir", dir, "search"}, 1, "give a query"},
+		{"bad query", []string{"-data-dir", dir, "search", "(budget"}, 1, "noteme search:"},
+		{"bad flag", []string{"-data-dir", dir, "search", "-limit", "x", "budget"}, 1, "invalid value"},
+		{"unknown backend", []string{"-data-dir", dir, "-backend", "csv", "search", "budget"}, 2, "unknown backend"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, out, stderr := runSearch(tt.args...)
+			if code != tt.code {
+				t.Errorf("exit code %d, want %d", code, tt.code)
+			}
+			if out != "" {
+				t.Errorf("stdout = %q, want nothing", out)
+			}
+			if !strings.Contains(stderr, tt.stderr) {
+				t.Errorf("stderr = %q, want it to mention %q", stderr, tt.stderr)
+			}
+		})
+	}
+}
+
+func TestSearchCommandLeavesFreshDirAlone(t *testing.T) {
+	for _, backend := range []string{"json", "markdown", "sqlite"} {
+		t.Run(backend, func(t *testing.T) {
+			dir := t.TempDir()
+			code, out, stderr := runSearch("-data-dir", dir, "-backend", backend, "search", "welcome")
+			if code != 0 || out != "" {
+				t.Errorf("exit code %d, stdout %q, stderr %q; want 0 and no results", code, out, stderr)
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 0 {
+				t.Errorf("search wrote %d files to a fresh data directory", len(entries))
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package model
+
+import (
+	"sort"
+	"strings"
+)
+
+// Folders nest with slashes: a note in "work/clients/acme" is also in
+// "work/clients" and "work". The empty folder is the root every note is in.
+
+// CleanFolder tidies a folder path as typed: spaces around the parts and
+// empty, "." and ".." parts are dropped.
+func CleanFolder(folder string) string {
+	var parts []string
+	for _, part := range strings.Split(strings.ReplaceAll(folder, "\\", "/"), "/") {
+		part = strings.TrimSpace(part)
+		if part != "" && part != "." && part != ".." {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// InFolder reports whether folder is dir or one of its subfolders.
+func InFolder(folder, dir string) bool {
+	folder, dir = CleanFolder(folder), CleanFolder(dir)
+	return dir == "" || folder == dir || strings.HasPrefix(folder, dir+"/")
+}
+
+// FolderNode is one folder in the tree made by FolderTree.
+type FolderNode struct {
+	Path        string // e.g. "work/clients"
+	Name        string // the last part, "clients"
+	Depth       int    // 0 for top-level folders
+	Notes       int    // notes in the folder and its subfolders
+	HasChildren bool
+}
+
+// Folders lists every folder notes are in, along with the folders above
+// them, sorted.
+func Folders(notes []Note) []string {
+	seen := map[string]bool{}
+	for _, n := range notes {
+		for f := CleanFolder(n.Folder); f != "" && !seen[f]; f = parentFolder(f) {
+			seen[f] = true
+		}
+	}
+	folders := make([]string, 0, len(seen))
+	for f := range seen {
+		folders = append(folders, f)
+	}
+	sort.Strings(folders)
+	return folders
+}
+
+// FolderTree lays out the folders of notes as a tree, each folder followed
+// by its subfolders in name order. Subfolders of the paths in collapsed
+// are left out.
+func FolderTree(notes []Note, collapsed map[string]bool) []FolderNode {
+	counts := map[string]int{}
+	for _, n := range notes {
+		for f := CleanFolder(n.Folder); f != ""; f = parentFolder(f) {
+			counts[f]++
+		}
+	}
+	folders := Folders(notes)
+	var nodes []FolderNode
+	for i, f := range folders {
+		if hiddenFolder(f, collapsed) {
+			continue
+		}
+		nodes = append(nodes, FolderNode{
+			Path:        f,
+			Name:        f[strings.LastIndex(f, "/")+1:],
+			Depth:       strings.Count(f, "/"),
+			Notes:       counts[f],
+			HasChildren: i+1 < len(folders) && strings.HasPrefix(folders[i+1], f+"/"),
+		})
+	}
+	return nodes
+}
+
+// hiddenFolder reports whether a folder above f is collapsed.
+func hiddenFolder(f string, collapsed map[string]bool) bool {
+	for p := parentFolder(f); p != ""; p = parentFolder(p) {
+		if collapsed[p] {
+			return true
+		}
+	}
+	return false
+}
+
+func parentFolder(f string) string {
+	if i := strings.LastIndex(f, "/"); i >= 0 {
+		return f[:i]
+	}
+	return ""
+}
+
+// RenameFolder moves the notes in from, and in its subfolders, to the same
+// place under to. It returns the moved notes, leaving notes untouched.
+func RenameFolder(notes []Note, from, to string) []Note {
+	from, to = CleanFolder(from), CleanFolder(to)
+	if from == "" || from == to {
+		return nil
+	}
+	var moved []Note
+	for _, n := range notes {
+		if folder := CleanFolder(n.Folder); InFolder(folder, from) {
+			n.Folder = strings.TrimPrefix(to+strings.TrimPrefix(folder, from), "/")
+			moved = append(moved, n)
+		}
+	}
+	return moved
+}
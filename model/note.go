@@ -8,4 +8,5 @@ type Note struct {
 	Content   string    `json:"content"`
 	CreatedAt time.Time `json:"created_at"`
 	Folder    string    `json:"folder"` // "daily", "weekly", "monthly", or custom
+	Tags      []string  `json:"tags,omitempty"`
 }
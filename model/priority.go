@@ -0,0 +1,60 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// Priority is how important a todo is. The zero value means none.
+type Priority string
+
+const (
+	PriorityNone   Priority = ""
+	PriorityLow    Priority = "low"
+	PriorityMedium Priority = "medium"
+	PriorityHigh   Priority = "high"
+	PriorityUrgent Priority = "urgent"
+)
+
+// Priorities lists the priorities from lowest to highest.
+var Priorities = []Priority{PriorityNone, PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent}
+
+// Rank is the priority's position in Priorities; unknown values rank as
+// none.
+func (p Priority) Rank() int {
+	for i, q := range Priorities {
+		if q == p {
+			return i
+		}
+	}
+	return 0
+}
+
+// Raise returns the next higher priority, stopping at urgent.
+func (p Priority) Raise() Priority {
+	return Priorities[min(p.Rank()+1, len(Priorities)-1)]
+}
+
+// Lower returns the next lower priority, stopping at none.
+func (p Priority) Lower() Priority {
+	return Priorities[max(p.Rank()-1, 0)]
+}
+
+// SortTodosByPriority orders open todos by priority, highest first, then by
+// due date, soonest first and undated last. Done todos go to the end. Todos
+// that tie keep their order.
+func SortTodosByPriority(todos []Todo, now time.Time) {
+	sort.SliceStable(todos, func(i, j int) bool {
+		a, b := todos[i], todos[j]
+		if a.Done != b.Done {
+			return !a.Done
+		}
+		if ra, rb := a.Priority.Rank(), b.Priority.Rank(); ra != rb {
+			return ra > rb
+		}
+		if a.Due.IsZero() != b.Due.IsZero() {
+			return !a.Due.IsZero()
+		}
+		return a.Due.Before(b.Due)
+	})
+}
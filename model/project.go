@@ -0,0 +1,64 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Project groups todos; a todo belongs to the project named by its
+// ProjectID.
+type Project struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ProjectName cleans up a project name as typed. Names are one word, as
+// that is how they are written in todo input (+name), so spaces become
+// dashes.
+func ProjectName(name string) string {
+	return strings.Join(strings.Fields(strings.TrimPrefix(strings.TrimSpace(name), "+")), "-")
+}
+
+// FindProject returns the project with the given name, ignoring case.
+func FindProject(projects []Project, name string) (Project, bool) {
+	for _, p := range projects {
+		if strings.EqualFold(p.Name, ProjectName(name)) {
+			return p, true
+		}
+	}
+	return Project{}, false
+}
+
+// Progress counts open and done todos.
+type Progress struct {
+	Open, Done int
+}
+
+func (p Progress) Total() int { return p.Open + p.Done }
+
+// Ratio is the share of todos that are done, 0 for no todos.
+func (p Progress) Ratio() float64 {
+	if p.Total() == 0 {
+		return 0
+	}
+	return float64(p.Done) / float64(p.Total())
+}
+
+// ProjectProgress counts the todos of every project, by project ID.
+func ProjectProgress(todos []Todo) map[string]Progress {
+	progress := map[string]Progress{}
+	for _, t := range todos {
+		if t.ProjectID == "" {
+			continue
+		}
+		p := progress[t.ProjectID]
+		if t.Done {
+			p.Done++
+		} else {
+			p.Open++
+		}
+		progress[t.ProjectID] = p
+	}
+	return progress
+}
@@ -0,0 +1,156 @@
+package model
+
+import (
+	"time"
+
+	"github.com/mtix28/noteme/rrule"
+)
+
+// Recurring reports whether todos with this frequency come back after they
+// are done. Anything other than Daily, Weekly and Monthly happens once.
+func (f Frequency) Recurring() bool {
+	return f == Daily || f == Weekly || f == Monthly
+}
+
+// PeriodStart returns the local midnight that starts the period containing
+// t: the same day, the Monday of the week or the first of the month, in t's
+// location. For non-recurring frequencies it returns t unchanged.
+func (f Frequency) PeriodStart(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch f {
+	case Daily:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	case Weekly:
+		back := (int(t.Weekday()) + 6) % 7 // days since Monday
+		return time.Date(y, m, d-back, 0, 0, 0, 0, t.Location())
+	case Monthly:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	}
+	return t
+}
+
+// NextPeriod returns the start of the period after the one containing t.
+// Dates are computed on the calendar, not by adding hours, so days that are
+// 23 or 25 hours long around DST changes are handled.
+func (f Frequency) NextPeriod(t time.Time) time.Time {
+	start := f.PeriodStart(t)
+	switch f {
+	case Daily:
+		return start.AddDate(0, 0, 1)
+	case Weekly:
+		return start.AddDate(0, 0, 7)
+	case Monthly:
+		return start.AddDate(0, 1, 0)
+	}
+	return t
+}
+
+// SetDone checks or unchecks the todo at the given time. Checking it records
+// a completion; unchecking takes back the last completion.
+func (t *Todo) SetDone(done bool, at time.Time) {
+	switch {
+	case done && !t.Done:
+		t.Completions = append(t.Completions, at)
+	case !done && t.Done && len(t.Completions) > 0:
+		t.Completions = t.Completions[:len(t.Completions)-1]
+	}
+	t.Done = done
+}
+
+// LastCompleted is when the todo was last checked off. Todos saved before
+// completions were recorded fall back to their creation time.
+func (t Todo) LastCompleted() time.Time {
+	if n := len(t.Completions); n > 0 {
+		return t.Completions[n-1]
+	}
+	return t.CreatedAt
+}
+
+// Rule returns the rule a frequency stands for: every day, every Monday or
+// every first of the month. ok is false for one-off todos.
+func (f Frequency) Rule() (rule rrule.Rule, ok bool) {
+	switch f {
+	case Daily:
+		return rrule.MustParse("FREQ=DAILY"), true
+	case Weekly:
+		return rrule.MustParse("FREQ=WEEKLY;BYDAY=MO"), true
+	case Monthly:
+		return rrule.MustParse("FREQ=MONTHLY;BYMONTHDAY=1"), true
+	}
+	return rrule.Rule{}, false
+}
+
+// FrequencyOf is the closest fixed frequency to r, stored next to an RRULE
+// so that older versions of noteme still repeat the todo.
+func FrequencyOf(r rrule.Rule) Frequency {
+	switch r.Freq {
+	case rrule.Daily:
+		return Daily
+	case rrule.Weekly:
+		return Weekly
+	case rrule.Monthly:
+		return Monthly
+	}
+	return Once
+}
+
+// Rule returns the todo's recurrence: its RRULE if it has a valid one,
+// otherwise the rule for its Frequency.
+func (t Todo) Rule() (rrule.Rule, bool) {
+	if t.Recurrence != "" {
+		if r, err := rrule.Parse(t.Recurrence); err == nil {
+			return r, true
+		}
+	}
+	return t.Frequency.Rule()
+}
+
+// Recurring reports whether the todo comes back after it is done.
+func (t Todo) Recurring() bool {
+	_, ok := t.Rule()
+	return ok
+}
+
+// NextDue is when a recurring todo is next due, in now's location: the
+// latest occurrence up to now if it is still open (the first one if none
+// has come yet), otherwise the first occurrence after its last completion.
+// Occurrences start on the first day from the todo's creation that matches
+// its rule, at local midnight. It is zero for one-off todos and once the
+// rule has run out.
+func (t Todo) NextDue(now time.Time) time.Time {
+	rule, ok := t.Rule()
+	if !ok {
+		return time.Time{}
+	}
+	c := t.CreatedAt.In(now.Location())
+	start := time.Date(c.Year(), c.Month(), c.Day(), 0, 0, 0, 0, now.Location())
+	if !t.Done {
+		first, ok := rule.First(start)
+		if !ok || now.Before(first) {
+			return first
+		}
+		due, _ := rule.Before(start, now, true)
+		return due
+	}
+	next, _ := rule.After(start, t.LastCompleted(), false)
+	return next
+}
+
+// DueNow reports whether the todo needs doing as of now.
+func (t Todo) DueNow(now time.Time) bool {
+	if !t.Done {
+		return true
+	}
+	due := t.NextDue(now)
+	return !due.IsZero() && !now.Before(due)
+}
+
+// Reset unchecks a recurring todo once its next occurrence has come. It
+// reports whether anything changed.
+func (t *Todo) Reset(now time.Time) bool {
+	if !t.Done || !t.DueNow(now) {
+		return false
+	}
+	t.Done = false
+	return true
+}
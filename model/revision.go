@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// Revision is a snapshot of a note as it was saved at SavedAt.
+type Revision struct {
+	ID      string    `json:"id"`
+	NoteID  string    `json:"note_id"`
+	SavedAt time.Time `json:"saved_at"`
+	Title   string    `json:"title"`
+	Content string    `json:"content"`
+	Folder  string    `json:"folder"`
+}
+
+// NewRevision records the current state of n.
+func NewRevision(id string, n Note, at time.Time) Revision {
+	return Revision{
+		ID:      id,
+		NoteID:  n.ID,
+		SavedAt: at,
+		Title:   n.Title,
+		Content: n.Content,
+		Folder:  n.Folder,
+	}
+}
+
+// Apply returns n with the title, content and folder of the revision.
+func (r Revision) Apply(n Note) Note {
+	n.Title = r.Title
+	n.Content = r.Content
+	n.Folder = r.Folder
+	return n
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// SavedSearch is a search query kept under a name. Notes see it as a smart
+// folder, todos as a filter. Query is written the way package query reads
+// it and is run afresh each time, so relative dates such as today move on.
+type SavedSearch struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FindSavedSearch returns the saved search with the given name, ignoring
+// case and surrounding spaces.
+func FindSavedSearch(searches []SavedSearch, name string) (SavedSearch, bool) {
+	for _, s := range searches {
+		if strings.EqualFold(s.Name, strings.TrimSpace(name)) {
+			return s, true
+		}
+	}
+	return SavedSearch{}, false
+}
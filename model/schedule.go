@@ -0,0 +1,84 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// Day is local midnight at the start of t's calendar day. Start dates, and
+// due dates without a time, are stored this way.
+func Day(t time.Time) time.Time {
+	return Daily.PeriodStart(t)
+}
+
+// Overdue reports whether an open todo's due date is a day that has passed,
+// or, if it was given a time, whether that time has passed.
+func (t Todo) Overdue(now time.Time) bool {
+	if t.Done || t.Due.IsZero() {
+		return false
+	}
+	if t.HasDueTime() {
+		return now.After(t.Due)
+	}
+	return t.Due.Before(Day(now))
+}
+
+// HasDueTime reports whether the due date has a time of day.
+func (t Todo) HasDueTime() bool {
+	return !t.Due.IsZero() && !t.Due.Equal(Day(t.Due))
+}
+
+// DueToday reports whether an open todo has to be done today: its due date
+// is today, or it recurs and its current occurrence fell on today.
+func (t Todo) DueToday(now time.Time) bool {
+	if t.Done {
+		return false
+	}
+	if !t.Due.IsZero() {
+		return Day(t.Due).Equal(Day(now))
+	}
+	return t.Recurring() && Day(t.NextDue(now)).Equal(Day(now))
+}
+
+// Started reports whether the todo's start date, if it has one, has come.
+func (t Todo) Started(now time.Time) bool {
+	return t.Start.IsZero() || !now.Before(Day(t.Start))
+}
+
+// SortTodos orders todos by urgency: overdue, due today, due later (soonest
+// first), no due date, not started yet, done. Within each of those, higher
+// priorities come first; todos that tie keep their order.
+func SortTodos(todos []Todo, now time.Time) {
+	rank := func(t Todo) int {
+		switch {
+		case t.Done:
+			return 5
+		case !t.Started(now):
+			return 4
+		case t.Overdue(now):
+			return 0
+		case t.DueToday(now):
+			return 1
+		case !t.Due.IsZero():
+			return 2
+		}
+		return 3
+	}
+	sort.SliceStable(todos, func(i, j int) bool {
+		ri, rj := rank(todos[i]), rank(todos[j])
+		if ri != rj {
+			return ri < rj
+		}
+		if ri == 0 || ri == 2 {
+			if !todos[i].Due.Equal(todos[j].Due) {
+				return todos[i].Due.Before(todos[j].Due)
+			}
+		}
+		if ri == 4 {
+			if !todos[i].Start.Equal(todos[j].Start) {
+				return todos[i].Start.Before(todos[j].Start)
+			}
+		}
+		return todos[i].Priority.Rank() > todos[j].Priority.Rank()
+	})
+}
@@ -0,0 +1,244 @@
+package model
+
+import (
+	"slices"
+	"sort"
+	"time"
+)
+
+// Subtasks: a todo whose ParentID names another todo is a step of it.
+// Subtasks are kept in Position order under their parent, while top-level
+// todos are shown in whatever order the list is sorted in. A subtask whose
+// parent is gone (deleted, or not loaded) is treated as a top-level todo,
+// so nothing is ever hidden.
+
+// TodoNode is a todo placed in the subtask tree.
+type TodoNode struct {
+	Todo
+	Depth    int // 0 for top-level todos
+	Subtasks int // direct subtasks
+	Finished int // direct subtasks that are done
+}
+
+// Tree flattens todos into display order: every todo is followed by its
+// subtasks, recursively. Top-level todos keep the order they are given in.
+// The subtasks of todos in collapsed are left out.
+func Tree(todos []Todo, collapsed map[string]bool) []TodoNode {
+	children, roots := subtaskIndex(todos)
+	var nodes []TodoNode
+	visited := make([]bool, len(todos))
+	var walk func(i, depth int)
+	walk = func(i, depth int) {
+		visited[i] = true
+		node := TodoNode{Todo: todos[i], Depth: depth, Subtasks: len(children[todos[i].ID])}
+		for _, c := range children[todos[i].ID] {
+			if todos[c].Done {
+				node.Finished++
+			}
+		}
+		nodes = append(nodes, node)
+		if collapsed[todos[i].ID] {
+			markVisited(children, todos, todos[i].ID, visited)
+			return
+		}
+		for _, c := range children[todos[i].ID] {
+			if !visited[c] {
+				walk(c, depth+1)
+			}
+		}
+	}
+	for _, i := range roots {
+		walk(i, 0)
+	}
+	// Todos whose parents form a cycle are never reached from the top;
+	// show them at the top level rather than lose them.
+	for i := range todos {
+		if !visited[i] {
+			walk(i, 0)
+		}
+	}
+	return nodes
+}
+
+func markVisited(children map[string][]int, todos []Todo, id string, visited []bool) {
+	for _, c := range children[id] {
+		if !visited[c] {
+			visited[c] = true
+			markVisited(children, todos, todos[c].ID, visited)
+		}
+	}
+}
+
+// subtaskIndex maps each todo ID to the indexes of its subtasks, in
+// Position order, and lists the top-level todos in their given order.
+func subtaskIndex(todos []Todo) (children map[string][]int, roots []int) {
+	ids := make(map[string]bool, len(todos))
+	for _, t := range todos {
+		ids[t.ID] = true
+	}
+	children = map[string][]int{}
+	for i, t := range todos {
+		if t.ParentID != "" && t.ParentID != t.ID && ids[t.ParentID] {
+			children[t.ParentID] = append(children[t.ParentID], i)
+		} else {
+			roots = append(roots, i)
+		}
+	}
+	for _, c := range children {
+		sort.SliceStable(c, func(a, b int) bool {
+			return todos[c[a]].Position < todos[c[b]].Position
+		})
+	}
+	return children, roots
+}
+
+// siblings returns the indexes of the todo with the given ID and of its
+// siblings in display order, and its place among them. ok is false if
+// there is no such todo.
+func siblings(todos []Todo, id string) (sibs []int, at int, ok bool) {
+	children, roots := subtaskIndex(todos)
+	for i, t := range todos {
+		if t.ID != id {
+			continue
+		}
+		sibs = roots
+		if c, isChild := children[t.ParentID]; isChild {
+			sibs = c
+		}
+		for at, s := range sibs {
+			if s == i {
+				return sibs, at, true
+			}
+		}
+	}
+	return nil, 0, false
+}
+
+// renumber gives the todos at sibs the positions 0, 1, ... and returns the
+// ones that changed.
+func renumber(todos []Todo, sibs []int) []Todo {
+	var changed []Todo
+	for pos, i := range sibs {
+		if todos[i].Position != pos {
+			todos[i].Position = pos
+			changed = append(changed, todos[i])
+		}
+	}
+	return changed
+}
+
+// SortTodosByPosition orders todos by Position, the order they were moved
+// into by hand. Todos that tie keep their order.
+func SortTodosByPosition(todos []Todo) {
+	sort.SliceStable(todos, func(i, j int) bool {
+		return todos[i].Position < todos[j].Position
+	})
+}
+
+// MoveTodo moves the todo with the given ID up (delta < 0) or down among
+// its siblings, renumbering their positions. It returns the todos that
+// changed, none if the todo cannot move that way.
+func MoveTodo(todos []Todo, id string, delta int) []Todo {
+	sibs, at, ok := siblings(todos, id)
+	to := at + delta
+	if !ok || delta == 0 || to < 0 || to >= len(sibs) {
+		return nil
+	}
+	order := slices.Delete(slices.Clone(sibs), at, at+1)
+	order = slices.Insert(order, to, sibs[at])
+	return renumber(todos, order)
+}
+
+// IndentTodo makes the todo with the given ID the last subtask of the
+// sibling above it. It returns the todos that changed.
+func IndentTodo(todos []Todo, id string) []Todo {
+	sibs, at, ok := siblings(todos, id)
+	if !ok || at == 0 {
+		return nil
+	}
+	i, parent := sibs[at], todos[sibs[at-1]].ID
+	todos[i].Position = NextPosition(todos, parent)
+	todos[i].ParentID = parent
+	return []Todo{todos[i]}
+}
+
+// NextPosition is the Position that puts a new subtask of parent after
+// the existing ones.
+func NextPosition(todos []Todo, parent string) int {
+	children, _ := subtaskIndex(todos)
+	if c := children[parent]; len(c) > 0 {
+		return todos[c[len(c)-1]].Position + 1
+	}
+	return 0
+}
+
+// OutdentTodo moves the todo with the given ID out of its parent, to just
+// below it. It returns the todos that changed.
+func OutdentTodo(todos []Todo, id string) []Todo {
+	i := indexOf(todos, id)
+	if i < 0 {
+		return nil
+	}
+	p := indexOf(todos, todos[i].ParentID)
+	if p < 0 {
+		return nil
+	}
+	todos[i].ParentID = todos[p].ParentID
+
+	sibs, _, _ := siblings(todos, todos[p].ID)
+	order := slices.DeleteFunc(slices.Clone(sibs), func(s int) bool { return s == i })
+	order = slices.Insert(order, slices.Index(order, p)+1, i)
+	changed := []Todo{todos[i]}
+	for _, t := range renumber(todos, order) {
+		if t.ID == id {
+			changed[0] = t
+		} else {
+			changed = append(changed, t)
+		}
+	}
+	return changed
+}
+
+func indexOf(todos []Todo, id string) int {
+	if id == "" {
+		return -1
+	}
+	for i, t := range todos {
+		if t.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetTodoDone checks off, or reopens, the todo with the given ID. Checking
+// off the last open subtask of a todo checks off the parent too, and so on
+// up the tree; reopening a subtask reopens the finished todos above it. It
+// returns the todos that changed.
+func SetTodoDone(todos []Todo, id string, done bool, at time.Time) []Todo {
+	i := indexOf(todos, id)
+	if i < 0 {
+		return nil
+	}
+	todos[i].SetDone(done, at)
+	changed := []Todo{todos[i]}
+
+	children, _ := subtaskIndex(todos)
+	seen := map[string]bool{id: true}
+	for p := indexOf(todos, todos[i].ParentID); p >= 0 && !seen[todos[p].ID]; p = indexOf(todos, todos[p].ParentID) {
+		seen[todos[p].ID] = true
+		if todos[p].Done == done {
+			break
+		}
+		if done {
+			for _, c := range children[todos[p].ID] {
+				if !todos[c].Done {
+					return changed
+				}
+			}
+		}
+		todos[p].SetDone(done, at)
+		changed = append(changed, todos[p])
+	}
+	return changed
+}
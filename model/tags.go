@@ -0,0 +1,92 @@
+package model
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// tagPattern matches #tag at the start of the text or after a space or
+// punctuation, so that URL fragments (example.com/#top), HTML entities
+// (&#39;) and Markdown headings ("# Title") are left alone. Tags start with
+// a letter and may contain digits, '-', '_' and '/' for nesting
+// (#work/clients).
+var tagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&/#])#(\p{L}[\p{L}\p{N}_/-]*)`)
+
+// ExtractTags returns the #tags in text, normalised with NormalizeTags.
+// Fenced code blocks are skipped.
+func ExtractTags(text string) []string {
+	var tags []string
+	inCode := false
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+		for _, m := range tagPattern.FindAllStringSubmatch(line, -1) {
+			tags = append(tags, m[1])
+		}
+	}
+	return NormalizeTags(tags)
+}
+
+// NormalizeTags lower-cases tags, strips a leading '#' and trailing
+// separators, and returns them sorted without duplicates.
+func NormalizeTags(tags []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimRight(strings.TrimPrefix(strings.TrimSpace(t), "#"), "/-_"))
+		if t != "" && !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// MatchTags reports whether tags satisfies the filter: all of want when
+// all is set, otherwise any of them. An empty filter matches everything.
+func MatchTags(tags, want []string, all bool) bool {
+	if len(want) == 0 {
+		return true
+	}
+	have := map[string]bool{}
+	for _, t := range tags {
+		have[t] = true
+	}
+	for _, w := range want {
+		if have[w] && !all {
+			return true
+		}
+		if !have[w] && all {
+			return false
+		}
+	}
+	return all
+}
+
+// TagSet is the note's tags: the ones it was saved with plus any #tags in
+// its title and content, which covers notes edited outside noteme.
+func (n Note) TagSet() []string {
+	return NormalizeTags(append(append([]string(nil), n.Tags...), ExtractTags(n.Title+"\n"+n.Content)...))
+}
+
+// UpdateTags sets n.Tags after an edit. Tags written as #tags in the text
+// follow the text; tags the note only carried in Tags, such as ones from
+// Markdown front matter, are kept. prev is the note before the edit, or the
+// zero Note for a new one.
+func (n *Note) UpdateTags(prev Note) {
+	fromText := ExtractTags(prev.Title + "\n" + prev.Content)
+	var kept []string
+	for _, t := range prev.Tags {
+		if !MatchTags(fromText, []string{t}, false) {
+			kept = append(kept, t)
+		}
+	}
+	n.Tags = NormalizeTags(append(kept, ExtractTags(n.Title+"\n"+n.Content)...))
+}
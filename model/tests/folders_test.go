@@ -0,0 +1,91 @@
+package model_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mtix28/noteme/model"
+)
+
+func TestCleanFolder(t *testing.T) {
+	for in, want := range map[string]string{
+		"work":                  "work",
+		" work / clients/acme/": "work/clients/acme",
+		`work\clients`:          "work/clients",
+		"../work//./x":          "work/x",
+		"  ":                    "",
+	} {
+		if got := model.CleanFolder(in); got != want {
+			t.Errorf("CleanFolder(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestInFolder(t *testing.T) {
+	for _, c := range []struct {
+		folder, dir string
+		want        bool
+	}{
+		{"work/clients/acme", "work", true},
+		{"work/clients", "work/clients", true},
+		{"workshop", "work", false},
+		{"work", "work/clients", false},
+		{"anything", "", true},
+	} {
+		if got := model.InFolder(c.folder, c.dir); got != c.want {
+			t.Errorf("InFolder(%q, %q) = %v, want %v", c.folder, c.dir, got, c.want)
+		}
+	}
+}
+
+var folderNotes = []model.Note{
+	{ID: "1", Folder: "work/clients/acme"},
+	{ID: "2", Folder: "work/clients/globex"},
+	{ID: "3", Folder: "work"},
+	{ID: "4", Folder: "daily"},
+	{ID: "5", Folder: ""},
+}
+
+func TestFolders(t *testing.T) {
+	want := []string{"daily", "work", "work/clients", "work/clients/acme", "work/clients/globex"}
+	if got := model.Folders(folderNotes); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Folders = %v, want %v", got, want)
+	}
+}
+
+func TestFolderTree(t *testing.T) {
+	tree := model.FolderTree(folderNotes, nil)
+	want := []model.FolderNode{
+		{Path: "daily", Name: "daily", Notes: 1},
+		{Path: "work", Name: "work", Notes: 3, HasChildren: true},
+		{Path: "work/clients", Name: "clients", Depth: 1, Notes: 2, HasChildren: true},
+		{Path: "work/clients/acme", Name: "acme", Depth: 2, Notes: 1},
+		{Path: "work/clients/globex", Name: "globex", Depth: 2, Notes: 1},
+	}
+	if !reflect.DeepEqual(tree, want) {
+		t.Fatalf("FolderTree = %+v\nwant %+v", tree, want)
+	}
+
+	folded := model.FolderTree(folderNotes, map[string]bool{"work/clients": true})
+	if len(folded) != 3 || folded[2].Path != "work/clients" || folded[2].Notes != 2 {
+		t.Fatalf("Expected the clients' subfolders hidden but counted, got %+v", folded)
+	}
+}
+
+func TestRenameFolder(t *testing.T) {
+	moved := model.RenameFolder(folderNotes, "work/clients", "clients")
+	got := map[string]string{}
+	for _, n := range moved {
+		got[n.ID] = n.Folder
+	}
+	want := map[string]string{"1": "clients/acme", "2": "clients/globex"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("RenameFolder moved %v, want %v", got, want)
+	}
+	if folderNotes[0].Folder != "work/clients/acme" {
+		t.Fatal("Expected RenameFolder to leave its input alone")
+	}
+	if moved := model.RenameFolder(folderNotes, "work", "work"); len(moved) != 0 {
+		t.Fatalf("Expected renaming a folder to itself to move nothing, got %+v", moved)
+	}
+}
@@ -0,0 +1,67 @@
+package model_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtix28/noteme/model"
+)
+
+func TestRaiseAndLowerPriority(t *testing.T) {
+	p := model.PriorityNone
+	for _, want := range []model.Priority{model.PriorityLow, model.PriorityMedium, model.PriorityHigh, model.PriorityUrgent, model.PriorityUrgent} {
+		if p = p.Raise(); p != want {
+			t.Fatalf("Raise = %q, want %q", p, want)
+		}
+	}
+	for _, want := range []model.Priority{model.PriorityHigh, model.PriorityMedium, model.PriorityLow, model.PriorityNone, model.PriorityNone} {
+		if p = p.Lower(); p != want {
+			t.Fatalf("Lower = %q, want %q", p, want)
+		}
+	}
+	if model.Priority("bogus").Rank() != 0 {
+		t.Fatal("Expected unknown priorities to rank as none")
+	}
+}
+
+func ids(todos []model.Todo) string {
+	var out []string
+	for _, t := range todos {
+		out = append(out, t.ID)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestSortTodosByPriority(t *testing.T) {
+	now := time.Date(2024, time.May, 8, 15, 0, 0, 0, time.Local)
+	today := model.Day(now)
+	todos := []model.Todo{
+		{ID: "none"},
+		{ID: "done-urgent", Priority: model.PriorityUrgent, Done: true},
+		{ID: "high-undated", Priority: model.PriorityHigh},
+		{ID: "high-later", Priority: model.PriorityHigh, Due: today.AddDate(0, 0, 5)},
+		{ID: "low", Priority: model.PriorityLow, Due: today.AddDate(0, 0, -1)},
+		{ID: "high-soon", Priority: model.PriorityHigh, Due: today.AddDate(0, 0, 1)},
+		{ID: "urgent", Priority: model.PriorityUrgent},
+	}
+	model.SortTodosByPriority(todos, now)
+	want := "urgent,high-soon,high-later,high-undated,low,none,done-urgent"
+	if got := ids(todos); got != want {
+		t.Fatalf("Got %s, want %s", got, want)
+	}
+}
+
+func TestSortTodosBreaksTiesByPriority(t *testing.T) {
+	now := time.Date(2024, time.May, 8, 15, 0, 0, 0, time.Local)
+	todos := []model.Todo{
+		{ID: "a"},
+		{ID: "b", Priority: model.PriorityMedium},
+		{ID: "c", Priority: model.PriorityUrgent},
+		{ID: "d", Due: model.Day(now)},
+	}
+	model.SortTodos(todos, now)
+	if got := ids(todos); got != "d,c,b,a" {
+		t.Fatalf("Got %s, want d,c,b,a", got)
+	}
+}
@@ -0,0 +1,56 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mtix28/noteme/model"
+)
+
+func TestProjectName(t *testing.T) {
+	for in, want := range map[string]string{
+		"holiday":         "holiday",
+		" +Holiday ":      "Holiday",
+		"summer  holiday": "summer-holiday",
+		"   ":             "",
+	} {
+		if got := model.ProjectName(in); got != want {
+			t.Errorf("ProjectName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFindProject(t *testing.T) {
+	projects := []model.Project{{ID: "1", Name: "holiday"}, {ID: "2", Name: "summer-house"}}
+	if p, ok := model.FindProject(projects, "+Holiday"); !ok || p.ID != "1" {
+		t.Fatalf("FindProject(+Holiday) = %+v, %v", p, ok)
+	}
+	if p, ok := model.FindProject(projects, "summer house"); !ok || p.ID != "2" {
+		t.Fatalf("FindProject(summer house) = %+v, %v", p, ok)
+	}
+	if _, ok := model.FindProject(projects, "work"); ok {
+		t.Fatal("Expected no project called work")
+	}
+}
+
+func TestProjectProgress(t *testing.T) {
+	progress := model.ProjectProgress([]model.Todo{
+		{ProjectID: "a", Done: true},
+		{ProjectID: "a"},
+		{ProjectID: "a"},
+		{ProjectID: "a", Done: true},
+		{ProjectID: "b"},
+		{},
+	})
+	if got := progress["a"]; got.Open != 2 || got.Done != 2 || got.Ratio() != 0.5 {
+		t.Fatalf("Progress of a = %+v", got)
+	}
+	if got := progress["b"]; got.Total() != 1 || got.Ratio() != 0 {
+		t.Fatalf("Progress of b = %+v", got)
+	}
+	if _, ok := progress[""]; ok {
+		t.Fatal("Expected todos without a project not to be counted")
+	}
+	if (model.Progress{}).Ratio() != 0 {
+		t.Fatal("Expected an empty project to be 0% done")
+	}
+}
@@ -0,0 +1,204 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mtix28/noteme/model"
+	"github.com/mtix28/noteme/rrule"
+)
+
+func mustLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s not available: %v", name, err)
+	}
+	return loc
+}
+
+func TestPeriodStart(t *testing.T) {
+	loc := mustLocation(t, "Europe/Berlin")
+	// Wednesday evening.
+	at := time.Date(2024, time.March, 27, 21, 30, 0, 0, loc)
+
+	tests := []struct {
+		freq model.Frequency
+		want time.Time
+	}{
+		{model.Daily, time.Date(2024, time.March, 27, 0, 0, 0, 0, loc)},
+		{model.Weekly, time.Date(2024, time.March, 25, 0, 0, 0, 0, loc)},
+		{model.Monthly, time.Date(2024, time.March, 1, 0, 0, 0, 0, loc)},
+		{model.Once, at},
+	}
+	for _, tt := range tests {
+		if got := tt.freq.PeriodStart(at); !got.Equal(tt.want) {
+			t.Errorf("%s: PeriodStart = %v, want %v", tt.freq, got, tt.want)
+		}
+	}
+
+	sunday := time.Date(2024, time.March, 31, 12, 0, 0, 0, loc)
+	if got := model.Weekly.PeriodStart(sunday); !got.Equal(time.Date(2024, time.March, 25, 0, 0, 0, 0, loc)) {
+		t.Errorf("Weeks should start on Monday, got %v", got)
+	}
+}
+
+func TestNextPeriodAcrossDST(t *testing.T) {
+	loc := mustLocation(t, "Europe/Berlin")
+	// Clocks go forward on 2024-03-31, so that day is 23 hours long.
+	at := time.Date(2024, time.March, 31, 8, 0, 0, 0, loc)
+	want := time.Date(2024, time.April, 1, 0, 0, 0, 0, loc)
+	if got := model.Daily.NextPeriod(at); !got.Equal(want) {
+		t.Fatalf("NextPeriod = %v, want %v", got, want)
+	}
+
+	jan31 := time.Date(2024, time.January, 31, 10, 0, 0, 0, loc)
+	if got := model.Monthly.NextPeriod(jan31); !got.Equal(time.Date(2024, time.February, 1, 0, 0, 0, 0, loc)) {
+		t.Fatalf("Monthly NextPeriod from Jan 31 = %v", got)
+	}
+}
+
+func TestRecurringTodoResets(t *testing.T) {
+	loc := mustLocation(t, "America/New_York")
+	morning := time.Date(2024, time.May, 6, 9, 0, 0, 0, loc) // Monday
+	todo := model.Todo{ID: "t1", Frequency: model.Daily, CreatedAt: morning.AddDate(0, 0, -3)}
+
+	todo.SetDone(true, morning)
+	if todo.Reset(morning.Add(10 * time.Hour)) {
+		t.Fatal("Expected the todo to stay done for the rest of the day")
+	}
+	if todo.DueNow(morning.Add(time.Hour)) {
+		t.Fatal("Expected a done daily todo not to be due again the same day")
+	}
+	if due := todo.NextDue(morning); !due.Equal(time.Date(2024, time.May, 7, 0, 0, 0, 0, loc)) {
+		t.Fatalf("NextDue = %v", due)
+	}
+
+	next := time.Date(2024, time.May, 7, 0, 0, 1, 0, loc)
+	if !todo.Reset(next) || todo.Done {
+		t.Fatal("Expected the todo to reset after midnight")
+	}
+	if len(todo.Completions) != 1 || !todo.Completions[0].Equal(morning) {
+		t.Fatalf("Expected the completion to be kept, got %v", todo.Completions)
+	}
+}
+
+func TestWeeklyTodoResetsOnMonday(t *testing.T) {
+	tuesday := time.Date(2024, time.May, 7, 18, 0, 0, 0, time.Local)
+	todo := model.Todo{Frequency: model.Weekly}
+	todo.SetDone(true, tuesday)
+
+	if todo.Reset(tuesday.AddDate(0, 0, 5)) { // Sunday
+		t.Fatal("Expected the todo to stay done until the week ends")
+	}
+	if !todo.Reset(tuesday.AddDate(0, 0, 6)) { // Monday
+		t.Fatal("Expected the todo to reset on Monday")
+	}
+}
+
+func TestOnceTodoNeverResets(t *testing.T) {
+	now := time.Now()
+	todo := model.Todo{Frequency: model.Once}
+	todo.SetDone(true, now.AddDate(-1, 0, 0))
+
+	if todo.Reset(now) || !todo.Done {
+		t.Fatal("Expected a one-off todo to stay done")
+	}
+	if !todo.NextDue(now).IsZero() {
+		t.Fatal("Expected a one-off todo to have no next due date")
+	}
+}
+
+func TestUncheckTakesBackCompletion(t *testing.T) {
+	now := time.Now()
+	todo := model.Todo{Frequency: model.Daily}
+	todo.SetDone(true, now.AddDate(0, 0, -1))
+	todo.Reset(now)
+	todo.SetDone(true, now)
+	todo.SetDone(false, now)
+
+	if len(todo.Completions) != 1 {
+		t.Fatalf("Expected only yesterday's completion, got %v", todo.Completions)
+	}
+}
+
+func TestLegacyDoneTodoResets(t *testing.T) {
+	now := time.Now()
+	// Saved before completions were recorded.
+	todo := model.Todo{Frequency: model.Daily, Done: true, CreatedAt: now.AddDate(0, 0, -2)}
+	if !todo.Reset(now) {
+		t.Fatal("Expected an old done daily todo to reset")
+	}
+}
+
+func TestRRuleTodo(t *testing.T) {
+	loc := mustLocation(t, "Europe/Berlin")
+	created := time.Date(2024, time.May, 1, 10, 0, 0, 0, loc)
+	friday := time.Date(2024, time.May, 3, 17, 0, 0, 0, loc)
+	todo := model.Todo{CreatedAt: created, Frequency: model.Daily, Recurrence: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"}
+
+	todo.SetDone(true, friday)
+	monday := time.Date(2024, time.May, 6, 0, 0, 0, 0, loc)
+	if due := todo.NextDue(friday); !due.Equal(monday) {
+		t.Fatalf("NextDue = %v, want %v", due, monday)
+	}
+	if todo.Reset(friday.AddDate(0, 0, 1)) {
+		t.Fatal("Expected no reset on Saturday")
+	}
+	if !todo.Reset(monday.Add(time.Minute)) {
+		t.Fatal("Expected a reset on Monday")
+	}
+}
+
+func TestRRuleTodoRunsOut(t *testing.T) {
+	created := time.Date(2024, time.May, 1, 10, 0, 0, 0, time.UTC)
+	todo := model.Todo{CreatedAt: created, Recurrence: "FREQ=DAILY;COUNT=2"}
+	todo.SetDone(true, created)
+	todo.Reset(created.AddDate(0, 0, 1))
+	todo.SetDone(true, created.AddDate(0, 0, 1))
+
+	if todo.Reset(created.AddDate(0, 1, 0)) {
+		t.Fatal("Expected the todo to stay done once its rule ran out")
+	}
+}
+
+func TestFrequencyMapsToRule(t *testing.T) {
+	for freq, want := range map[model.Frequency]string{
+		model.Daily:   "FREQ=DAILY",
+		model.Weekly:  "FREQ=WEEKLY;BYDAY=MO",
+		model.Monthly: "FREQ=MONTHLY;BYMONTHDAY=1",
+	} {
+		r, ok := freq.Rule()
+		if !ok || r.String() != want {
+			t.Errorf("%s.Rule() = %q, %v, want %q", freq, r, ok, want)
+		}
+		if model.FrequencyOf(r) != freq {
+			t.Errorf("FrequencyOf(%q) = %s, want %s", r, model.FrequencyOf(r), freq)
+		}
+	}
+	if _, ok := model.Once.Rule(); ok {
+		t.Error("Expected Once to have no rule")
+	}
+}
+
+func TestInvalidRRuleFallsBackToFrequency(t *testing.T) {
+	todo := model.Todo{Frequency: model.Weekly, Recurrence: "FREQ=SOMETIMES"}
+	r, ok := todo.Rule()
+	if !ok || r.Freq != rrule.Weekly {
+		t.Fatalf("Rule = %v, %v", r, ok)
+	}
+}
+
+func TestRecurringTodoBeforeFirstOccurrence(t *testing.T) {
+	loc := mustLocation(t, "Europe/Berlin")
+	created := time.Date(2024, time.May, 3, 9, 0, 0, 0, loc)
+	todo := model.Todo{CreatedAt: created, Recurrence: "FREQ=MONTHLY;BYMONTHDAY=15"}
+
+	now := created.Add(time.Hour)
+	if due := todo.NextDue(now); !due.Equal(time.Date(2024, time.May, 15, 0, 0, 0, 0, loc)) {
+		t.Fatalf("NextDue = %v, want the 15th", due)
+	}
+	if todo.DueToday(now) {
+		t.Fatal("Expected a todo first due on the 15th not to be due on the 3rd")
+	}
+}
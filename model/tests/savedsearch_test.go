@@ -0,0 +1,20 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mtix28/noteme/model"
+)
+
+func TestFindSavedSearch(t *testing.T) {
+	searches := []model.SavedSearch{
+		{ID: "1", Name: "Urgent at work", Query: "folder:work tag:urgent"},
+		{ID: "2", Name: "Today", Query: "created:today"},
+	}
+	if s, ok := model.FindSavedSearch(searches, "  urgent AT work "); !ok || s.ID != "1" {
+		t.Errorf("FindSavedSearch by name = %+v, %v; want the first search", s, ok)
+	}
+	if _, ok := model.FindSavedSearch(searches, "Urgent"); ok {
+		t.Error("FindSavedSearch matched a prefix of a name")
+	}
+}
@@ -0,0 +1,77 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mtix28/noteme/model"
+)
+
+func TestOverdueAndDueToday(t *testing.T) {
+	now := time.Date(2024, time.May, 8, 15, 0, 0, 0, time.Local)
+	today := model.Day(now)
+
+	tests := []struct {
+		name     string
+		todo     model.Todo
+		overdue  bool
+		dueToday bool
+	}{
+		{"no due date", model.Todo{}, false, false},
+		{"due yesterday", model.Todo{Due: today.AddDate(0, 0, -1)}, true, false},
+		{"due today", model.Todo{Due: today}, false, true},
+		{"due tomorrow", model.Todo{Due: today.AddDate(0, 0, 1)}, false, false},
+		{"done late", model.Todo{Due: today.AddDate(0, 0, -1), Done: true}, false, false},
+		{"daily", model.Todo{Frequency: model.Daily, CreatedAt: today.AddDate(0, 0, -5)}, false, true},
+		{"weekly since monday", model.Todo{Frequency: model.Weekly, CreatedAt: today.AddDate(0, 0, -30)}, false, false},
+	}
+	for _, tt := range tests {
+		if got := tt.todo.Overdue(now); got != tt.overdue {
+			t.Errorf("%s: Overdue = %v, want %v", tt.name, got, tt.overdue)
+		}
+		if got := tt.todo.DueToday(now); got != tt.dueToday {
+			t.Errorf("%s: DueToday = %v, want %v", tt.name, got, tt.dueToday)
+		}
+	}
+}
+
+func TestStarted(t *testing.T) {
+	now := time.Date(2024, time.May, 8, 0, 30, 0, 0, time.Local)
+	if !(model.Todo{}).Started(now) {
+		t.Error("Expected a todo without start date to be started")
+	}
+	if !(model.Todo{Start: model.Day(now)}).Started(now) {
+		t.Error("Expected a todo starting today to be started")
+	}
+	if (model.Todo{Start: model.Day(now).AddDate(0, 0, 1)}).Started(now) {
+		t.Error("Expected a todo starting tomorrow not to be started")
+	}
+}
+
+func TestSortTodos(t *testing.T) {
+	now := time.Date(2024, time.May, 8, 12, 0, 0, 0, time.Local)
+	today := model.Day(now)
+	todos := []model.Todo{
+		{ID: "done", Done: true},
+		{ID: "plain"},
+		{ID: "later", Due: today.AddDate(0, 0, 5)},
+		{ID: "future", Start: today.AddDate(0, 0, 2)},
+		{ID: "soon", Due: today.AddDate(0, 0, 1)},
+		{ID: "today", Due: today},
+		{ID: "late", Due: today.AddDate(0, 0, -1)},
+		{ID: "very late", Due: today.AddDate(0, 0, -9)},
+		{ID: "plain 2"},
+	}
+	model.SortTodos(todos, now)
+
+	want := []string{"very late", "late", "today", "soon", "later", "plain", "plain 2", "future", "done"}
+	for i, id := range want {
+		if todos[i].ID != id {
+			var got []string
+			for _, t := range todos {
+				got = append(got, t.ID)
+			}
+			t.Fatalf("Order = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package model_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtix28/noteme/model"
+)
+
+// outline renders the tree as "id(depth)" pairs, with progress for todos
+// that have subtasks.
+func outline(nodes []model.TodoNode) string {
+	var out []string
+	for _, n := range nodes {
+		s := fmt.Sprintf("%s(%d)", n.ID, n.Depth)
+		if n.Subtasks > 0 {
+			s += fmt.Sprintf("%d/%d", n.Finished, n.Subtasks)
+		}
+		out = append(out, s)
+	}
+	return strings.Join(out, " ")
+}
+
+func trip() []model.Todo {
+	return []model.Todo{
+		{ID: "trip"},
+		{ID: "tickets", ParentID: "trip", Position: 1, Done: true},
+		{ID: "pack", ParentID: "trip", Position: 0},
+		{ID: "socks", ParentID: "pack"},
+		{ID: "taxes"},
+		{ID: "lost", ParentID: "deleted"},
+	}
+}
+
+func TestTree(t *testing.T) {
+	todos := trip()
+	if got, want := outline(model.Tree(todos, nil)), "trip(0)1/2 pack(1)0/1 socks(2) tickets(1) taxes(0) lost(0)"; got != want {
+		t.Fatalf("Tree =\n%s\nwant\n%s", got, want)
+	}
+	if got, want := outline(model.Tree(todos, map[string]bool{"pack": true})), "trip(0)1/2 pack(1)0/1 tickets(1) taxes(0) lost(0)"; got != want {
+		t.Fatalf("Folded tree =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTreeSurvivesCycles(t *testing.T) {
+	todos := []model.Todo{{ID: "a", ParentID: "b"}, {ID: "b", ParentID: "a"}, {ID: "c", ParentID: "c"}}
+	if got := outline(model.Tree(todos, nil)); got != "c(0) a(0)0/1 b(1)0/1" {
+		t.Fatalf("Tree = %s", got)
+	}
+}
+
+func TestMoveTodo(t *testing.T) {
+	todos := trip()
+	if changed := model.MoveTodo(todos, "pack", -1); changed != nil {
+		t.Fatalf("Expected the first subtask not to move up, changed %v", changed)
+	}
+	changed := model.MoveTodo(todos, "pack", 1)
+	if len(changed) != 2 {
+		t.Fatalf("Expected both siblings to be renumbered, got %+v", changed)
+	}
+	if got := outline(model.Tree(todos, nil)); !strings.HasPrefix(got, "trip(0)1/2 tickets(1) pack(1)") {
+		t.Fatalf("Tree after move = %s", got)
+	}
+}
+
+func TestIndentAndOutdent(t *testing.T) {
+	todos := trip()
+	if model.IndentTodo(todos, "trip") != nil {
+		t.Fatal("Expected the first todo not to indent")
+	}
+	model.IndentTodo(todos, "taxes")
+	if got := outline(model.Tree(todos, nil)); got != "trip(0)1/3 pack(1)0/1 socks(2) tickets(1) taxes(1) lost(0)" {
+		t.Fatalf("Tree after indent = %s", got)
+	}
+
+	model.OutdentTodo(todos, "socks")
+	if got := outline(model.Tree(todos, nil)); got != "trip(0)1/4 pack(1) socks(1) tickets(1) taxes(1) lost(0)" {
+		t.Fatalf("Tree after outdent = %s", got)
+	}
+	model.OutdentTodo(todos, "socks")
+	if got := outline(model.Tree(todos, nil)); got != "trip(0)1/3 pack(1) tickets(1) taxes(1) socks(0) lost(0)" {
+		t.Fatalf("Tree after second outdent = %s", got)
+	}
+	if model.OutdentTodo(todos, "socks") != nil {
+		t.Fatal("Expected a top-level todo not to outdent")
+	}
+}
+
+func TestSetTodoDoneCompletesParents(t *testing.T) {
+	now := time.Now()
+	todos := trip()
+
+	changed := model.SetTodoDone(todos, "socks", true, now)
+	if len(changed) != 3 || changed[1].ID != "pack" || changed[2].ID != "trip" {
+		t.Fatalf("Expected socks, pack and trip to be checked off, got %+v", changed)
+	}
+
+	changed = model.SetTodoDone(todos, "tickets", false, now)
+	if len(changed) != 2 || changed[1].ID != "trip" || changed[1].Done {
+		t.Fatalf("Expected trip to reopen, got %+v", changed)
+	}
+	for _, td := range todos {
+		if td.ID == "pack" && !td.Done {
+			t.Fatal("Expected pack to stay done")
+		}
+	}
+}
+
+func TestSetTodoDoneWaitsForOpenSiblings(t *testing.T) {
+	todos := trip()
+	todos[1].Done = false // tickets
+	if changed := model.SetTodoDone(todos, "socks", true, time.Now()); len(changed) != 2 {
+		t.Fatalf("Expected only socks and pack to change, got %+v", changed)
+	}
+}
@@ -0,0 +1,77 @@
+package model_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mtix28/noteme/model"
+)
+
+func TestExtractTags(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"plain text", ""},
+		{"#Work and #home, #work again", "home,work"},
+		{"nested #work/clients tag", "work/clients"},
+		{"(#paren) and #end.", "end,paren"},
+		{"# Heading\n## Sub", ""},
+		{"see example.com/#top or a#b", ""},
+		{"it&#39;s fine", ""},
+		{"#2024 is a year", ""},
+		{"```\n#include <stdio.h>\n```\n#c", "c"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(model.ExtractTags(tt.text), ","); got != tt.want {
+			t.Errorf("ExtractTags(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	got := model.NormalizeTags([]string{"#Home", " work ", "home", "", "work/", "#"})
+	if strings.Join(got, ",") != "home,work" {
+		t.Fatalf("NormalizeTags = %v", got)
+	}
+}
+
+func TestMatchTags(t *testing.T) {
+	tags := []string{"home", "urgent"}
+	tests := []struct {
+		want     []string
+		all, any bool
+	}{
+		{nil, true, true},
+		{[]string{"home"}, true, true},
+		{[]string{"home", "work"}, false, true},
+		{[]string{"home", "urgent"}, true, true},
+		{[]string{"work"}, false, false},
+	}
+	for _, tt := range tests {
+		if got := model.MatchTags(tags, tt.want, true); got != tt.all {
+			t.Errorf("MatchTags(%v, all) = %v, want %v", tt.want, got, tt.all)
+		}
+		if got := model.MatchTags(tags, tt.want, false); got != tt.any {
+			t.Errorf("MatchTags(%v, any) = %v, want %v", tt.want, got, tt.any)
+		}
+	}
+}
+
+func TestUpdateTags(t *testing.T) {
+	// "reading" came from front matter, "draft" from the text.
+	prev := model.Note{Title: "Books", Content: "#draft list", Tags: []string{"draft", "reading"}}
+
+	n := model.Note{Title: "Books", Content: "final list #done"}
+	n.UpdateTags(prev)
+	if strings.Join(n.Tags, ",") != "done,reading" {
+		t.Fatalf("Tags = %v, want [done reading]", n.Tags)
+	}
+
+	var fresh model.Note
+	fresh.Content = "#Idea"
+	fresh.UpdateTags(model.Note{})
+	if strings.Join(fresh.Tags, ",") != "idea" {
+		t.Fatalf("Tags = %v, want [idea]", fresh.Tags)
+	}
+}
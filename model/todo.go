@@ -17,4 +17,27 @@ type Todo struct {
 	Done      bool      `json:"done"`
 	CreatedAt time.Time `json:"created_at"`
 	Frequency Frequency `json:"frequency"` // "daily", "weekly", "monthly"
+
+	// Recurrence is an RFC 5545 RRULE such as "FREQ=WEEKLY;BYDAY=MO,WE".
+	// When set it takes precedence over Frequency.
+	Recurrence string `json:"rrule,omitempty"`
+
+	// Due is when the todo has to be done by: local midnight for a whole
+	// day, or a time on that day. Start is the day it can be started on.
+	// Both are zero when not set.
+	Due   time.Time `json:"due,omitzero"`
+	Start time.Time `json:"start,omitzero"`
+
+	// ParentID makes the todo a subtask of another one; Position orders it
+	// among that todo's subtasks. See Tree.
+	ParentID string `json:"parent_id,omitempty"`
+	Position int    `json:"position,omitempty"`
+
+	ProjectID string   `json:"project_id,omitempty"` // see Project
+	Priority  Priority `json:"priority,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+
+	// Completions records every time the todo was checked off, oldest
+	// first. Recurring todos keep theirs across resets.
+	Completions []time.Time `json:"completions,omitempty"`
 }
@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// Kinds of item that can end up in the trash.
+const (
+	KindNote = "note"
+	KindTodo = "todo"
+)
+
+// TrashItem is a deleted note or todo, kept until it is restored or purged.
+// Exactly one of Note and Todo is set, matching Kind.
+type TrashItem struct {
+	ID        string    `json:"id"` // ID of the deleted note or todo
+	Kind      string    `json:"kind"`
+	DeletedAt time.Time `json:"deleted_at"`
+	Note      *Note     `json:"note,omitempty"`
+	Todo      *Todo     `json:"todo,omitempty"`
+}
+
+func TrashedNote(n Note, at time.Time) TrashItem {
+	return TrashItem{ID: n.ID, Kind: KindNote, DeletedAt: at, Note: &n}
+}
+
+func TrashedTodo(t Todo, at time.Time) TrashItem {
+	return TrashItem{ID: t.ID, Kind: KindTodo, DeletedAt: at, Todo: &t}
+}
+
+// Label is the note title or todo text.
+func (t TrashItem) Label() string {
+	switch {
+	case t.Note != nil:
+		return t.Note.Title
+	case t.Todo != nil:
+		return t.Todo.Content
+	}
+	return t.ID
+}
@@ -0,0 +1,362 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mtix28/noteme/model"
+)
+
+// SyntaxError is a query that could not be parsed, with the column
+// (counting from 1) of the offending part.
+type SyntaxError struct {
+	Col int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string { return fmt.Sprintf("col %d: %s", e.Col, e.Msg) }
+
+// Fields are the names usable in field:value terms.
+var Fields = []string{"folder", "tag", "title", "body", "project", "priority", "is", "created", "due"}
+
+type tokenKind int
+
+const (
+	tWord tokenKind = iota
+	tPhrase
+	tLParen
+	tRParen
+	tAnd
+	tOr
+	tNot
+	tEOF
+)
+
+type token struct {
+	kind tokenKind
+	text string // a word as written, or the inside of a phrase
+	pos  int    // byte offset in the query
+
+	// A word of the form field:value, where value may be a "phrase". op
+	// is ":" or a comparison.
+	field, op, value string
+}
+
+// lex splits a query into tokens.
+func lex(s string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tRParen, text: ")", pos: i})
+			i++
+		case c == '"':
+			end := strings.IndexByte(s[i+1:], '"')
+			if end < 0 {
+				return nil, &SyntaxError{i + 1, `missing closing "`}
+			}
+			tokens = append(tokens, token{kind: tPhrase, text: s[i+1 : i+1+end], pos: i})
+			i += end + 2
+		case c == '-' && i+1 < len(s) && !strings.ContainsRune(" \t\n)", rune(s[i+1])):
+			tokens = append(tokens, token{kind: tNot, text: "-", pos: i})
+			i++
+		default:
+			start := i
+			for i < len(s) && !strings.ContainsRune(" \t\n()\"", rune(s[i])) {
+				i++
+			}
+			t := token{kind: tWord, text: s[start:i], pos: start}
+			switch t.text {
+			case "AND", "&&":
+				t.kind = tAnd
+			case "OR", "||", "|":
+				t.kind = tOr
+			case "NOT":
+				t.kind = tNot
+			}
+			if field, rest, ok := strings.Cut(t.text, ":"); ok && t.kind == tWord && isField(field) {
+				t.field = strings.ToLower(field)
+				for _, op := range []string{">=", "<=", ">", "<", "="} {
+					if strings.HasPrefix(rest, op) {
+						t.op, rest = op, rest[len(op):]
+						break
+					}
+				}
+				if t.op == "" || t.op == "=" {
+					t.op = ":"
+				}
+				t.value = rest
+				// field:"a phrase"
+				if rest == "" && i < len(s) && s[i] == '"' {
+					end := strings.IndexByte(s[i+1:], '"')
+					if end < 0 {
+						return nil, &SyntaxError{i + 1, `missing closing "`}
+					}
+					t.value = s[i+1 : i+1+end]
+					i += end + 2
+					t.text = s[start:i]
+				}
+			}
+			tokens = append(tokens, t)
+		}
+	}
+	return append(tokens, token{kind: tEOF, pos: len(s)}), nil
+}
+
+// isField reports whether s names one of the Fields, so that other words
+// with a colon (12:30, http://…, re:budget) are searched for as text.
+func isField(s string) bool {
+	for _, f := range Fields {
+		if strings.EqualFold(s, f) {
+			return true
+		}
+	}
+	return false
+}
+
+type parser struct {
+	tokens []token
+	at     int
+	now    time.Time
+}
+
+// Parse parses a query. Dates such as today are relative to now.
+func Parse(s string, now time.Time) (*Query, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, now: now}
+	root, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tEOF {
+		return nil, p.errorf(t, "unexpected %s", t.text)
+	}
+	return &Query{Root: root}, nil
+}
+
+func (p *parser) peek() token { return p.tokens[p.at] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.at]
+	if t.kind != tEOF {
+		p.at++
+	}
+	return t
+}
+
+func (p *parser) errorf(t token, format string, args ...any) error {
+	return &SyntaxError{t.pos + 1, fmt.Sprintf(format, args...)}
+}
+
+// or := and (OR and)*
+func (p *parser) or() (Expr, error) {
+	var terms Or
+	for {
+		x, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		if x != nil {
+			terms = append(terms, x)
+		}
+		if p.peek().kind != tOr {
+			break
+		}
+		op := p.next()
+		if k := p.peek().kind; k == tEOF || k == tRParen || k == tOr {
+			return nil, p.errorf(op, "OR needs a term on both sides")
+		}
+		if x == nil {
+			return nil, p.errorf(op, "OR needs a term on both sides")
+		}
+	}
+	switch len(terms) {
+	case 0:
+		return nil, nil
+	case 1:
+		return terms[0], nil
+	}
+	return terms, nil
+}
+
+// and := unary ([AND] unary)*
+func (p *parser) and() (Expr, error) {
+	var terms And
+	for {
+		switch t := p.peek(); t.kind {
+		case tEOF, tRParen, tOr:
+			switch len(terms) {
+			case 0:
+				return nil, nil
+			case 1:
+				return terms[0], nil
+			}
+			return terms, nil
+		case tAnd:
+			p.next()
+			if k := p.peek().kind; len(terms) == 0 || k == tEOF || k == tRParen || k == tOr || k == tAnd {
+				return nil, p.errorf(t, "AND needs a term on both sides")
+			}
+			continue
+		}
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if x != nil {
+			terms = append(terms, x)
+		}
+	}
+}
+
+// unary := (NOT | -) unary | primary
+func (p *parser) unary() (Expr, error) {
+	if t := p.peek(); t.kind == tNot {
+		p.next()
+		if k := p.peek().kind; k == tEOF || k == tRParen || k == tOr || k == tAnd {
+			return nil, p.errorf(t, "%s needs a term after it", t.text)
+		}
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if x == nil {
+			return nil, p.errorf(t, "%s needs a term after it", t.text)
+		}
+		return Not{x}, nil
+	}
+	return p.primary()
+}
+
+// primary := ( or ) | word | phrase | field:value
+func (p *parser) primary() (Expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tLParen:
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tRParen {
+			return nil, p.errorf(t, "missing ) for this (")
+		}
+		p.next()
+		if x == nil {
+			return nil, p.errorf(t, "nothing between ( and )")
+		}
+		return x, nil
+	case tPhrase:
+		return textExpr(t.text), nil
+	case tWord:
+		if t.field != "" {
+			return p.field(t)
+		}
+		return textExpr(t.text), nil
+	}
+	return nil, p.errorf(t, "unexpected %s", t.text)
+}
+
+// textExpr is a Word for text with one word in it and a Phrase for more;
+// text without letters or digits is left out.
+func textExpr(text string) Expr {
+	tokens := Tokenize(text)
+	switch len(tokens) {
+	case 0:
+		return nil
+	case 1:
+		return Word(tokens[0].Word)
+	}
+	words := make(Phrase, len(tokens))
+	for i, t := range tokens {
+		words[i] = t.Word
+	}
+	return words
+}
+
+func (p *parser) field(t token) (Expr, error) {
+	if t.value == "" {
+		return nil, p.errorf(t, "%s: needs a value", t.field)
+	}
+	if t.op != ":" && t.field != "priority" && t.field != "created" && t.field != "due" {
+		return nil, p.errorf(t, "%s: can't be compared with %s", t.field, t.op)
+	}
+	value := strings.TrimSpace(t.value)
+	switch t.field {
+	case "folder":
+		return Folder(model.CleanFolder(value)), nil
+	case "tag":
+		tags := model.NormalizeTags([]string{value})
+		if len(tags) == 0 {
+			return nil, p.errorf(t, "tag: needs a value")
+		}
+		return Tag(tags[0]), nil
+	case "title", "body":
+		x := textExpr(value)
+		if x == nil {
+			return nil, p.errorf(t, "%s: needs a word to look for", t.field)
+		}
+		return Scoped{t.field, x}, nil
+	case "project":
+		return Project(model.ProjectName(value)), nil
+	case "is":
+		switch v := strings.ToLower(value); v {
+		case "done", "open", "note", "todo":
+			return Is(v), nil
+		}
+		return nil, p.errorf(t, "is:%s: want is:done, is:open, is:note or is:todo", value)
+	case "priority":
+		level := model.Priority(strings.ToLower(value))
+		if level == "none" {
+			level = model.PriorityNone
+		}
+		for _, known := range model.Priorities {
+			if level == known {
+				return Priority{t.op, level}, nil
+			}
+		}
+		return nil, p.errorf(t, "priority:%s: want none, low, medium, high or urgent", value)
+	case "created", "due":
+		from, to, ok := period(value, p.now)
+		if !ok {
+			return nil, p.errorf(t, "%s: %s is not a date (try 2026-01-31, 2026-01, 2026, today or yesterday)", t.field, value)
+		}
+		return Date{t.field, t.op, from, to, value}, nil
+	}
+	return textExpr(t.text), nil
+}
+
+// period reads a day, month or year, local time, as the range [from, to).
+func period(s string, now time.Time) (from, to time.Time, ok bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(s) {
+	case "today":
+		return today, today.AddDate(0, 0, 1), true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), today, true
+	}
+	for _, f := range []struct {
+		layout       string
+		years, month int
+		days         int
+	}{
+		{"2006-01-02", 0, 0, 1},
+		{"2006-01", 0, 1, 0},
+		{"2006", 1, 0, 0},
+	} {
+		if t, err := time.ParseInLocation(f.layout, s, now.Location()); err == nil {
+			return t, t.AddDate(f.years, f.month, f.days), true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
@@ -0,0 +1,369 @@
+// Package query parses search queries such as
+//
+//	folder:work tag:urgent -is:done created:>2026-01-01 "exact phrase"
+//
+// into a tree of expressions that matches notes and todos. Words and
+// phrases match the text of an item, field:value terms match one of its
+// fields, and terms combine with AND (implied between terms), OR, NOT (or a
+// leading -) and parentheses.
+package query
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mtix28/noteme/model"
+)
+
+// Kind says whether an item is a note or a todo.
+type Kind string
+
+const (
+	KindNote Kind = "note"
+	KindTodo Kind = "todo"
+)
+
+// Item is a note or a todo as queries see it.
+type Item struct {
+	Kind     Kind
+	ID       string
+	Title    string // a note's title or a todo's text
+	Body     string // a note's content
+	Folder   string
+	Tags     []string
+	Project  string // the name of a todo's project
+	Priority model.Priority
+	Done     bool
+	Created  time.Time
+	Due      time.Time
+}
+
+// Note is the searchable part of a note.
+func Note(n model.Note) Item {
+	return Item{Kind: KindNote, ID: n.ID, Title: n.Title, Body: n.Content, Folder: n.Folder, Tags: n.TagSet(), Created: n.CreatedAt}
+}
+
+// Todo is the searchable part of a todo, in the project with the given
+// name.
+func Todo(t model.Todo, project string) Item {
+	return Item{
+		Kind:     KindTodo,
+		ID:       t.ID,
+		Title:    t.Content,
+		Tags:     t.Tags,
+		Project:  project,
+		Priority: t.Priority,
+		Done:     t.Done,
+		Created:  t.CreatedAt,
+		Due:      t.Due,
+	}
+}
+
+// Query is a parsed query.
+type Query struct {
+	Root Expr // nil when the query is empty
+}
+
+// Match reports whether it matches the query. An empty query matches
+// everything.
+func (q *Query) Match(it Item) bool {
+	return q.Root == nil || q.Root.Match(it)
+}
+
+// Empty reports whether the query has no terms.
+func (q *Query) Empty() bool { return q.Root == nil }
+
+// Words lists the words the query looks for in the text of items, leaving
+// out the ones it excludes. They are what results are ranked and
+// highlighted by.
+func (q *Query) Words() []string {
+	var words []string
+	var walk func(e Expr)
+	walk = func(e Expr) {
+		switch e := e.(type) {
+		case And:
+			for _, x := range e {
+				walk(x)
+			}
+		case Or:
+			for _, x := range e {
+				walk(x)
+			}
+		case Word:
+			words = append(words, string(e))
+		case Phrase:
+			words = append(words, e...)
+		case Scoped:
+			walk(e.X)
+		}
+	}
+	walk(q.Root)
+	return words
+}
+
+func (q *Query) String() string {
+	if q.Root == nil {
+		return ""
+	}
+	return q.Root.String()
+}
+
+// Expr is a node of a parsed query.
+type Expr interface {
+	Match(it Item) bool
+	String() string
+}
+
+// And matches items that match all of its terms.
+type And []Expr
+
+func (e And) Match(it Item) bool {
+	for _, x := range e {
+		if !x.Match(it) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e And) String() string { return group(e, " AND ") }
+
+// Or matches items that match any of its terms.
+type Or []Expr
+
+func (e Or) Match(it Item) bool {
+	for _, x := range e {
+		if x.Match(it) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e Or) String() string { return group(e, " OR ") }
+
+func group(exprs []Expr, op string) string {
+	parts := make([]string, len(exprs))
+	for i, x := range exprs {
+		parts[i] = x.String()
+	}
+	return "(" + strings.Join(parts, op) + ")"
+}
+
+// Not matches items that X does not match.
+type Not struct{ X Expr }
+
+func (e Not) Match(it Item) bool { return !e.X.Match(it) }
+func (e Not) String() string     { return "NOT " + e.X.String() }
+
+// Word matches items with a word that starts with it, in any of their
+// text: title, body, folder, tags or project.
+type Word string
+
+func (e Word) Match(it Item) bool {
+	return e.matchText(it.Title, it.Body, it.Folder, strings.Join(it.Tags, " "), it.Project)
+}
+
+func (e Word) matchText(texts ...string) bool {
+	for _, text := range texts {
+		for _, t := range Tokenize(text) {
+			if strings.HasPrefix(t.Word, string(e)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (e Word) String() string { return string(e) }
+
+// Phrase matches items whose title or body has its words one after the
+// other.
+type Phrase []string
+
+func (e Phrase) Match(it Item) bool { return e.matchText(it.Title, it.Body) }
+
+func (e Phrase) matchText(texts ...string) bool {
+	for _, text := range texts {
+		tokens := Tokenize(text)
+		for i := 0; i+len(e) <= len(tokens); i++ {
+			j := 0
+			for j < len(e) && tokens[i+j].Word == e[j] {
+				j++
+			}
+			if j == len(e) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (e Phrase) String() string { return `"` + strings.Join(e, " ") + `"` }
+
+// Scoped is a word or phrase looked for in the title or the body only, as
+// in title:budget.
+type Scoped struct {
+	Field string // "title" or "body"
+	X     Expr   // a Word or a Phrase
+}
+
+func (e Scoped) Match(it Item) bool {
+	text := it.Title
+	if e.Field == "body" {
+		text = it.Body
+	}
+	switch x := e.X.(type) {
+	case Word:
+		return x.matchText(text)
+	case Phrase:
+		return x.matchText(text)
+	}
+	return false
+}
+
+func (e Scoped) String() string { return e.Field + ":" + e.X.String() }
+
+// Folder matches notes in the folder or its subfolders.
+type Folder string
+
+func (e Folder) Match(it Item) bool {
+	return it.Kind == KindNote && model.InFolder(it.Folder, string(e))
+}
+
+func (e Folder) String() string { return "folder:" + string(e) }
+
+// Tag matches items with the tag or a tag nested under it.
+type Tag string
+
+func (e Tag) Match(it Item) bool {
+	for _, t := range it.Tags {
+		if t == string(e) || strings.HasPrefix(t, string(e)+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (e Tag) String() string { return "tag:" + string(e) }
+
+// Project matches todos in the project.
+type Project string
+
+func (e Project) Match(it Item) bool { return strings.EqualFold(it.Project, string(e)) }
+func (e Project) String() string     { return "project:" + string(e) }
+
+// Is matches by kind or state: done, open, note or todo.
+type Is string
+
+func (e Is) Match(it Item) bool {
+	switch e {
+	case "done":
+		return it.Kind == KindTodo && it.Done
+	case "open":
+		return it.Kind == KindTodo && !it.Done
+	case "note":
+		return it.Kind == KindNote
+	case "todo":
+		return it.Kind == KindTodo
+	}
+	return false
+}
+
+func (e Is) String() string { return "is:" + string(e) }
+
+// Priority compares a todo's priority, as in priority:>=high.
+type Priority struct {
+	Op    string // one of ":", ">", ">=", "<", "<="
+	Level model.Priority
+}
+
+func (e Priority) Match(it Item) bool {
+	if it.Kind != KindTodo {
+		return false
+	}
+	return compare(e.Op, it.Priority.Rank()-e.Level.Rank())
+}
+
+func (e Priority) String() string { return "priority" + e.Op + levelName(e.Level) }
+
+func levelName(p model.Priority) string {
+	if p == model.PriorityNone {
+		return "none"
+	}
+	return string(p)
+}
+
+// Date compares when an item was created or is due with a day, month or
+// year, as in created:>2026-01-01. The period is [From, To).
+type Date struct {
+	Field    string // "created" or "due"
+	Op       string
+	From, To time.Time
+	Text     string // the period as written
+}
+
+func (e Date) Match(it Item) bool {
+	t := it.Created
+	if e.Field == "due" {
+		t = it.Due
+	}
+	if t.IsZero() {
+		return false
+	}
+	switch e.Op {
+	case ">":
+		return !t.Before(e.To)
+	case ">=":
+		return !t.Before(e.From)
+	case "<":
+		return t.Before(e.From)
+	case "<=":
+		return t.Before(e.To)
+	}
+	return !t.Before(e.From) && t.Before(e.To)
+}
+
+func (e Date) String() string { return e.Field + e.Op + e.Text }
+
+// compare applies op to the sign of a difference.
+func compare(op string, diff int) bool {
+	switch op {
+	case ">":
+		return diff > 0
+	case ">=":
+		return diff >= 0
+	case "<":
+		return diff < 0
+	case "<=":
+		return diff <= 0
+	}
+	return diff == 0
+}
+
+// Token is a word of a text, lower-cased, with its byte range.
+type Token struct {
+	Word       string
+	Start, End int
+}
+
+// Tokenize splits text into words of letters and digits.
+func Tokenize(text string) []Token {
+	var tokens []Token
+	start := -1
+	for i, r := range text {
+		word := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case word && start < 0:
+			start = i
+		case !word && start >= 0:
+			tokens = append(tokens, Token{strings.ToLower(text[start:i]), start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, Token{strings.ToLower(text[start:]), start, len(text)})
+	}
+	return tokens
+}
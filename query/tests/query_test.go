@@ -0,0 +1,156 @@
+package query_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mtix28/noteme/model"
+	"github.com/mtix28/noteme/query"
+)
+
+var now = time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+
+func TestParseTree(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                            "",
+		"Budget":                      "budget",
+		"budget meeting":              "(budget AND meeting)",
+		"a b OR -c":                   "((a AND b) OR NOT c)",
+		"a AND (b OR c)":              "(a AND (b OR c))",
+		"NOT (a | b)":                 "NOT (a OR b)",
+		`"Exact  phrase" e-mail`:      `("exact phrase" AND "e mail")`,
+		"folder:Work/ tag:#Urgent":    "(folder:Work AND tag:urgent)",
+		"-is:done":                    "NOT is:done",
+		"get things done":             "(get AND things AND done)",
+		`title:"weekly sync" body:q3`: `(title:"weekly sync" AND body:q3)`,
+		"created:>2026-01-01":         "created>2026-01-01",
+		"due:<=today priority:>=high": "(due<=today AND priority>=high)",
+		"priority:none project:+Trip": "(priority:none AND project:Trip)",
+		"at 12:30 !!":                 `(at AND "12 30")`,
+		"http://example.com":          `"http example com"`,
+		"re:meeting Folder:home":      `("re meeting" AND folder:home)`,
+		"todo:":                       "todo",
+	} {
+		q, err := query.Parse(in, now)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", in, err)
+			continue
+		}
+		if got := q.String(); got != want {
+			t.Errorf("Parse(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for in, want := range map[string]string{
+		`budget "open`:      `col 8: missing closing "`,
+		"(a OR b":           "col 1: missing ) for this (",
+		"a)":                "col 2: unexpected )",
+		"a OR":              "col 3: OR needs a term on both sides",
+		"AND a":             "col 1: AND needs a term on both sides",
+		"a NOT":             "col 3: NOT needs a term after it",
+		"()":                "col 1: nothing between ( and )",
+		"x created:>2026-1": "col 3: created: 2026-1 is not a date (try 2026-01-31, 2026-01, 2026, today or yesterday)",
+		"is:later":          "col 1: is:later: want is:done, is:open, is:note or is:todo",
+		"priority:top":      "col 1: priority:top: want none, low, medium, high or urgent",
+		"folder:>work":      "col 1: folder: can't be compared with >",
+		"tag:":              "col 1: tag: needs a value",
+	} {
+		_, err := query.Parse(in, now)
+		var syntax *query.SyntaxError
+		if !errors.As(err, &syntax) {
+			t.Errorf("Parse(%q) = %v, want a syntax error", in, err)
+			continue
+		}
+		if err.Error() != want {
+			t.Errorf("Parse(%q) error = %q, want %q", in, err, want)
+		}
+	}
+}
+
+var (
+	budget = query.Note(model.Note{
+		Title:     "Budget meeting",
+		Content:   "Agreed on the exact phrase we use. #urgent/today",
+		Folder:    "work/finance",
+		CreatedAt: time.Date(2026, 2, 3, 9, 0, 0, 0, time.UTC),
+	})
+	slides = query.Todo(model.Todo{
+		Content:   "Prepare slides",
+		Done:      true,
+		Priority:  model.PriorityHigh,
+		Tags:      []string{"work"},
+		CreatedAt: time.Date(2025, 12, 31, 9, 0, 0, 0, time.UTC),
+		Due:       time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC),
+	}, "offsite")
+)
+
+func TestMatch(t *testing.T) {
+	for _, c := range []struct {
+		query          string
+		budget, slides bool
+	}{
+		{`folder:work tag:urgent -is:done created:>2026-01-01 "exact phrase"`, true, false},
+		{"budg", true, false},
+		{"slides OR finance", true, true},
+		{"folder:work", true, false},
+		{"tag:work", false, true},
+		{"is:done", false, true},
+		{"done", false, false},
+		{"-done", true, true},
+		{"is:open", false, false},
+		{"is:note", true, false},
+		{"created:2026-02", true, false},
+		{"created:<2026", false, true},
+		{"due:today", false, true},
+		{"due:>today", false, false},
+		{"priority:>=medium", false, true},
+		{"priority:urgent", false, false},
+		{"project:offsite", false, true},
+		{"title:meeting", true, false},
+		{"body:meeting", false, false},
+		{`"phrase exact"`, false, false},
+		{"", true, true},
+	} {
+		q, err := query.Parse(c.query, now)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", c.query, err)
+		}
+		if got := q.Match(budget); got != c.budget {
+			t.Errorf("%q matches the note = %v, want %v", c.query, got, c.budget)
+		}
+		if got := q.Match(slides); got != c.slides {
+			t.Errorf("%q matches the todo = %v, want %v", c.query, got, c.slides)
+		}
+	}
+}
+
+func TestDoneIsAWord(t *testing.T) {
+	note := query.Note(model.Note{Title: "Get things done"})
+	q, err := query.Parse("get things done", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.Match(note) {
+		t.Errorf("%s doesn't match a note titled %q", q, "Get things done")
+	}
+}
+
+func TestWords(t *testing.T) {
+	q, err := query.Parse(`budget -draft (slides OR "next year") title:q3 tag:work`, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := q.Words()
+	want := []string{"budget", "slides", "next", "year", "q3"}
+	if len(got) != len(want) {
+		t.Fatalf("Words = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Words = %v, want %v", got, want)
+		}
+	}
+}
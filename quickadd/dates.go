@@ -0,0 +1,256 @@
+package quickadd
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mtix28/noteme/model"
+)
+
+// date parses "<keyword> <day> [at] [time]" at the current position, where
+// the keyword (due, by, start, ...) has already been recognised. Either the
+// day or the time may be left out: "due 5pm" is today at five. On success
+// it stores the result in dst and moves past the phrase.
+func (p *parser) date(dst *time.Time) bool {
+	i := 1
+	day, n, hasDay := p.day(i)
+	if hasDay {
+		i += n
+	} else {
+		day = model.Day(p.now)
+	}
+	hour, minute, n, hasClock := p.clock(i)
+	if hasClock {
+		i += n
+		day = time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+	}
+	if !hasDay && !hasClock {
+		return false
+	}
+	*dst = day
+	p.pos += i
+	return true
+}
+
+// day parses a calendar day i tokens ahead and returns local midnight and
+// the number of tokens used.
+func (p *parser) day(i int) (time.Time, int, bool) {
+	today := model.Day(p.now)
+	w := p.peek(i)
+	switch w {
+	case "today":
+		return today, 1, true
+	case "tomorrow", "tmrw":
+		return today.AddDate(0, 0, 1), 1, true
+	case "next":
+		switch next := p.peek(i + 1); next {
+		case "week":
+			return model.Weekly.NextPeriod(p.now), 2, true
+		case "month":
+			return model.Monthly.NextPeriod(p.now), 2, true
+		default:
+			if wd, ok := weekday(next); ok {
+				return nextWeekday(today, wd, false), 2, true
+			}
+		}
+		return time.Time{}, 0, false
+	case "in":
+		n, ok := count(p.peek(i + 1))
+		if !ok {
+			return time.Time{}, 0, false
+		}
+		switch strings.TrimSuffix(p.peek(i+2), "s") {
+		case "day":
+			return today.AddDate(0, 0, n), 3, true
+		case "week":
+			return today.AddDate(0, 0, 7*n), 3, true
+		case "month":
+			return today.AddDate(0, n, 0), 3, true
+		}
+		return time.Time{}, 0, false
+	}
+
+	if wd, ok := weekday(w); ok {
+		return nextWeekday(today, wd, true), 1, true
+	}
+	if t, ok := parseOffset(w, p.now); ok {
+		return t, 1, true
+	}
+	if t, err := time.ParseInLocation("2006-01-02", w, p.now.Location()); err == nil {
+		return t, 1, true
+	}
+	// "may 10", "may 10th", "10 may", "10th may"
+	if m, ok := month(w); ok {
+		if d, ok := monthDay(p.peek(i + 1)); ok {
+			return p.upcoming(m, d), 2, true
+		}
+	}
+	if d, ok := monthDay(w); ok {
+		if m, ok := month(p.peek(i + 1)); ok {
+			return p.upcoming(m, d), 2, true
+		}
+	}
+	return time.Time{}, 0, false
+}
+
+// upcoming is the next d of month m, today included.
+func (p *parser) upcoming(m time.Month, d int) time.Time {
+	today := model.Day(p.now)
+	t := time.Date(today.Year(), m, d, 0, 0, 0, 0, today.Location())
+	if t.Before(today) {
+		t = t.AddDate(1, 0, 0)
+	}
+	return t
+}
+
+// clock parses a time of day i tokens ahead: 9am, 9:30pm, 21:00, 9 am,
+// noon, optionally after "at".
+func (p *parser) clock(i int) (hour, minute, n int, ok bool) {
+	at := 0
+	if p.peek(i) == "at" {
+		at = 1
+	}
+	w := p.peek(i + at)
+	switch w {
+	case "noon":
+		return 12, 0, at + 1, true
+	case "midnight":
+		return 0, 0, at + 1, true
+	}
+	if suffix := p.peek(i + at + 1); suffix == "am" || suffix == "pm" {
+		if h, m, ok := parseClock(w + suffix); ok {
+			return h, m, at + 2, true
+		}
+	}
+	if h, m, ok := parseClock(w); ok {
+		return h, m, at + 1, true
+	}
+	return 0, 0, 0, false
+}
+
+func parseClock(s string) (hour, minute int, ok bool) {
+	ampm := ""
+	if strings.HasSuffix(s, "am") || strings.HasSuffix(s, "pm") {
+		ampm, s = s[len(s)-2:], s[:len(s)-2]
+	}
+	hs, ms, hasMinutes := strings.Cut(s, ":")
+	if !hasMinutes && ampm == "" {
+		return 0, 0, false // a bare number is not a time
+	}
+	h, err := strconv.Atoi(hs)
+	if err != nil {
+		return 0, 0, false
+	}
+	m := 0
+	if hasMinutes {
+		if len(ms) != 2 {
+			return 0, 0, false
+		}
+		if m, err = strconv.Atoi(ms); err != nil || m > 59 {
+			return 0, 0, false
+		}
+	}
+	switch ampm {
+	case "":
+		if h > 23 {
+			return 0, 0, false
+		}
+	default:
+		if h < 1 || h > 12 {
+			return 0, 0, false
+		}
+		h %= 12
+		if ampm == "pm" {
+			h += 12
+		}
+	}
+	return h, m, true
+}
+
+// parseOffset reads +3d or +2w.
+func parseOffset(s string, now time.Time) (time.Time, bool) {
+	if len(s) < 3 || s[0] != '+' {
+		return time.Time{}, false
+	}
+	n, err := strconv.Atoi(s[1 : len(s)-1])
+	if err != nil || n < 0 {
+		return time.Time{}, false
+	}
+	today := model.Day(now)
+	switch s[len(s)-1] {
+	case 'd':
+		return today.AddDate(0, 0, n), true
+	case 'w':
+		return today.AddDate(0, 0, 7*n), true
+	}
+	return time.Time{}, false
+}
+
+// nextWeekday is the first wd from today on, or after today when
+// includeToday is false.
+func nextWeekday(today time.Time, wd time.Weekday, includeToday bool) time.Time {
+	days := (int(wd) - int(today.Weekday()) + 7) % 7
+	if days == 0 && !includeToday {
+		days = 7
+	}
+	return today.AddDate(0, 0, days)
+}
+
+func weekday(s string) (time.Weekday, bool) {
+	s = strings.TrimSuffix(s, ",")
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		name := strings.ToLower(wd.String())
+		if s == name || s == name[:3] || s == name+"s" {
+			return wd, true
+		}
+	}
+	switch s {
+	case "tue", "tues":
+		return time.Tuesday, true
+	case "thu", "thur", "thurs":
+		return time.Thursday, true
+	}
+	return 0, false
+}
+
+func month(s string) (time.Month, bool) {
+	for m := time.January; m <= time.December; m++ {
+		name := strings.ToLower(m.String())
+		if s == name || s == name[:3] || (m == time.September && s == "sept") {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+// monthDay reads 1..31, with or without an ordinal suffix (1st, 22nd).
+func monthDay(s string) (int, bool) {
+	for _, suffix := range []string{"st", "nd", "rd", "th"} {
+		s = strings.TrimSuffix(s, suffix)
+	}
+	d, err := strconv.Atoi(s)
+	if err != nil || d < 1 || d > 31 {
+		return 0, false
+	}
+	return d, true
+}
+
+// count reads a small positive number, in digits or as a word.
+func count(s string) (int, bool) {
+	switch s {
+	case "a", "an", "one":
+		return 1, true
+	case "two", "other":
+		return 2, true
+	case "three":
+		return 3, true
+	case "four":
+		return 4, true
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, false
+	}
+	return n, true
+}
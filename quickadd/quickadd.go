@@ -0,0 +1,207 @@
+// Package quickadd parses the one-line todo input, such as
+//
+//	pay rent every month on the 1st !high #home due fri 9am
+//
+// into a description and the fields hidden in it: recurrence, priority,
+// tags, project and due/start dates. Words that are not part of a
+// recognised phrase stay in the description, so "read every book" is left
+// alone.
+package quickadd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mtix28/noteme/model"
+	"github.com/mtix28/noteme/rrule"
+)
+
+// Result is what Parse found in the input.
+type Result struct {
+	Content string
+
+	// Frequency is set for the fixed frequencies (/daily, every day, every
+	// week on monday, ...). Recurrence holds an RRULE for anything else.
+	Frequency  model.Frequency
+	Recurrence string
+
+	Due   time.Time // zero when not given; midnight unless a time was given
+	Start time.Time
+
+	Priority model.Priority
+	Tags     []string
+	Project  string
+}
+
+// Parse reads input relative to now. It only fails for explicit syntax
+// that is wrong, like an invalid RRULE: or due: value; phrases it does not
+// understand are kept as part of the description.
+func Parse(input string, now time.Time) (Result, error) {
+	p := &parser{toks: tokenize(input), now: now, res: Result{Frequency: model.Once}}
+	for p.pos < len(p.toks) {
+		ok, err := p.step()
+		if err != nil {
+			return Result{}, err
+		}
+		if !ok {
+			p.words = append(p.words, p.toks[p.pos].text)
+			p.pos++
+		}
+	}
+	p.res.Content = strings.Join(p.words, " ")
+	if p.rule != nil {
+		p.res.Frequency, p.res.Recurrence = frequencyFor(*p.rule)
+	}
+	return p.res, nil
+}
+
+type token struct {
+	text  string // as typed
+	lower string
+}
+
+func tokenize(s string) []token {
+	var toks []token
+	for _, f := range strings.Fields(s) {
+		toks = append(toks, token{text: f, lower: strings.ToLower(f)})
+	}
+	return toks
+}
+
+type parser struct {
+	toks  []token
+	pos   int
+	now   time.Time
+	res   Result
+	rule  *rrule.Rule
+	words []string
+}
+
+// peek returns the lower-cased token i places ahead, or "".
+func (p *parser) peek(i int) string {
+	if p.pos+i < len(p.toks) {
+		return p.toks[p.pos+i].lower
+	}
+	return ""
+}
+
+// step tries every phrase at the current position. It reports whether one
+// matched, in which case the position has moved past it.
+func (p *parser) step() (bool, error) {
+	tok := p.toks[p.pos]
+	switch {
+	case tok.lower == "/daily", tok.lower == "/weekly", tok.lower == "/monthly":
+		rule, _ := model.Frequency(tok.lower[1:]).Rule()
+		p.rule = &rule
+		p.pos++
+		return true, nil
+
+	case strings.HasPrefix(tok.lower, "rrule:"):
+		rule, err := rrule.Parse(tok.text)
+		if err != nil {
+			return false, err
+		}
+		p.rule = &rule
+		p.pos++
+		return true, nil
+
+	case len(tok.lower) > 1 && tok.lower[0] == '#' && startsWithLetter(tok.text[1:]):
+		// Tags start with a letter and end with a letter or digit, as in
+		// notes, so "issue #42" keeps its number and "#home," loses its comma.
+		p.addTag(strings.TrimRightFunc(tok.text[1:], func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}))
+		p.pos++
+		return true, nil
+
+	case len(tok.lower) > 1 && tok.lower[0] == '+':
+		if _, ok := parseOffset(tok.lower, p.now); ok {
+			return false, nil // "+3d" without due is just a word
+		}
+		p.res.Project = tok.text[1:]
+		p.pos++
+		return true, nil
+
+	case strings.HasPrefix(tok.lower, "project:") && len(tok.lower) > len("project:"):
+		p.res.Project = tok.text[len("project:"):]
+		p.pos++
+		return true, nil
+
+	case len(tok.lower) > 1 && tok.lower[0] == '!':
+		if prio, ok := priorities[tok.lower[1:]]; ok {
+			p.res.Priority = prio
+			p.pos++
+			return true, nil
+		}
+		return false, nil
+
+	case tok.lower == "every":
+		return p.every(), nil
+	}
+
+	for _, kw := range []string{"due", "start"} {
+		if strings.HasPrefix(tok.lower, kw+":") {
+			return true, p.colonDate(kw, tok.lower[len(kw)+1:])
+		}
+	}
+	switch tok.lower {
+	case "due", "by":
+		return p.date(&p.res.Due), nil
+	case "start", "starting", "from":
+		return p.date(&p.res.Start), nil
+	}
+	return false, nil
+}
+
+var priorities = map[string]model.Priority{
+	"low":    model.PriorityLow,
+	"med":    model.PriorityMedium,
+	"medium": model.PriorityMedium,
+	"high":   model.PriorityHigh,
+	"urgent": model.PriorityUrgent,
+}
+
+func startsWithLetter(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsLetter(r)
+}
+
+func (p *parser) addTag(tag string) {
+	for _, t := range p.res.Tags {
+		if strings.EqualFold(t, tag) {
+			return
+		}
+	}
+	p.res.Tags = append(p.res.Tags, tag)
+}
+
+// colonDate handles the compact due:fri and start:2024-05-10 forms. Unlike
+// the spelled-out phrases, a bad date here is an error.
+func (p *parser) colonDate(kw, value string) error {
+	sub := &parser{toks: tokenize(kw + " " + value), now: p.now}
+	var day time.Time
+	if !sub.date(&day) || sub.pos != len(sub.toks) {
+		return fmt.Errorf("%s:%s is not a date (try 2024-05-10, today, tomorrow, fri or +3d)", kw, value)
+	}
+	if kw == "due" {
+		p.res.Due = day
+	} else {
+		p.res.Start = day
+	}
+	p.pos++
+	return nil
+}
+
+// frequencyFor stores the rules that match a fixed frequency the old way,
+// so todos stay readable by older versions.
+func frequencyFor(r rrule.Rule) (model.Frequency, string) {
+	for _, f := range []model.Frequency{model.Daily, model.Weekly, model.Monthly} {
+		if fr, _ := f.Rule(); fr.String() == r.String() {
+			return f, ""
+		}
+	}
+	return model.FrequencyOf(r), r.String()
+}
@@ -0,0 +1,163 @@
+package quickadd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mtix28/noteme/rrule"
+)
+
+// every parses a recurrence phrase starting at "every":
+//
+//	every day | every 2 weeks | every other month | every year
+//	every weekday | every weekend | every mon and thu
+//	every 2nd tuesday | every last friday
+//	every week on fri | every month on the 1st | every month on the last day
+//
+// optionally followed by "until <day>" or "<n> times".
+func (p *parser) every() bool {
+	i := 1
+	r := rrule.Rule{Interval: 1}
+
+	if n, wd, ok := ordinalWeekday(p.peek(i), p.peek(i+1)); ok {
+		r.Freq = rrule.Monthly
+		r.ByDay = []rrule.Day{{N: n, Weekday: wd}}
+		i += 2
+	} else {
+		if n, ok := count(p.peek(i)); ok && p.peek(i) != "a" {
+			r.Interval = n
+			i++
+		}
+		switch unit := strings.TrimSuffix(p.peek(i), "s"); unit {
+		case "day":
+			r.Freq = rrule.Daily
+		case "week":
+			r.Freq = rrule.Weekly
+		case "month":
+			r.Freq = rrule.Monthly
+		case "year":
+			r.Freq = rrule.Yearly
+		case "weekday", "workday":
+			r.Freq = rrule.Weekly
+			r.ByDay = days(time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+		case "weekend":
+			r.Freq = rrule.Weekly
+			r.ByDay = days(time.Saturday, time.Sunday)
+		default:
+			list, n := p.weekdays(i)
+			if n == 0 {
+				return false
+			}
+			r.Freq = rrule.Weekly
+			r.ByDay = list
+			i += n - 1
+		}
+		i++
+	}
+
+	// "on ..." narrows weeks down to weekdays and months to days.
+	if p.peek(i) == "on" {
+		j := i + 1
+		if p.peek(j) == "the" {
+			j++
+		}
+		switch r.Freq {
+		case rrule.Weekly:
+			if list, n := p.weekdays(j); n > 0 && r.ByDay == nil {
+				r.ByDay = list
+				i = j + n
+			}
+		case rrule.Monthly, rrule.Yearly:
+			if p.peek(j) == "last" && p.peek(j+1) == "day" {
+				r.ByMonthDay = []int{-1}
+				i = j + 2
+			} else if n, wd, ok := ordinalWeekday(p.peek(j), p.peek(j+1)); ok {
+				r.ByDay = []rrule.Day{{N: n, Weekday: wd}}
+				i = j + 2
+			} else if d, ok := monthDay(p.peek(j)); ok {
+				r.ByMonthDay = []int{d}
+				i = j + 1
+			}
+		}
+	}
+
+	switch {
+	case p.peek(i) == "until":
+		if until, n, ok := p.day(i + 1); ok {
+			r.Until = until.AddDate(0, 0, 1).Add(-time.Second)
+			i += 1 + n
+		}
+	case p.peek(i+1) == "times":
+		if n, ok := count(p.peek(i)); ok {
+			r.Count = n
+			i += 2
+		}
+	}
+
+	p.rule = &r
+	p.pos += i
+	return true
+}
+
+// weekdays reads a list like "mon", "mon,wed", "monday and thursday" or
+// "mon, wed and fri" i tokens ahead. It returns how many tokens it used.
+func (p *parser) weekdays(i int) ([]rrule.Day, int) {
+	var list []rrule.Day
+	used := 0
+	for {
+		w := p.peek(i + used)
+		if w == "and" && len(list) > 0 {
+			if _, ok := weekday(p.peek(i + used + 1)); ok {
+				used++
+				continue
+			}
+			break
+		}
+		parts := strings.Split(strings.TrimSuffix(w, ","), ",")
+		var found []rrule.Day
+		for _, part := range parts {
+			wd, ok := weekday(part)
+			if !ok {
+				found = nil
+				break
+			}
+			found = append(found, rrule.Day{Weekday: wd})
+		}
+		if found == nil {
+			break
+		}
+		list = append(list, found...)
+		used++
+	}
+	return list, used
+}
+
+// ordinalWeekday reads "2nd tuesday", "second tue" or "last fri".
+func ordinalWeekday(ord, day string) (int, time.Weekday, bool) {
+	wd, ok := weekday(day)
+	if !ok {
+		return 0, 0, false
+	}
+	n, ok := ordinals[ord]
+	if !ok {
+		return 0, 0, false
+	}
+	return n, wd, true
+}
+
+var ordinals = map[string]int{
+	"1st": 1, "first": 1,
+	"2nd": 2, "second": 2,
+	"3rd": 3, "third": 3,
+	"4th": 4, "fourth": 4,
+	"5th": 5, "fifth": 5,
+	"last": -1,
+}
+
+func days(wds ...time.Weekday) []rrule.Day {
+	out := make([]rrule.Day, len(wds))
+	for i, wd := range wds {
+		out[i] = rrule.Day{Weekday: wd}
+	}
+	return out
+}
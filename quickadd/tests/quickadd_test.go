@@ -0,0 +1,191 @@
+package quickadd_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtix28/noteme/model"
+	"github.com/mtix28/noteme/quickadd"
+	"github.com/mtix28/noteme/rrule"
+)
+
+// Wednesday morning.
+var now = time.Date(2024, time.May, 8, 10, 0, 0, 0, time.Local)
+
+func on(m time.Month, d int) time.Time {
+	return time.Date(2024, m, d, 0, 0, 0, 0, time.Local)
+}
+
+func at(m time.Month, d, hour, minute int) time.Time {
+	return time.Date(2024, m, d, hour, minute, 0, 0, time.Local)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  quickadd.Result
+	}{
+		{
+			input: "buy milk",
+			want:  quickadd.Result{Content: "buy milk"},
+		},
+		{
+			input: "pay rent every month on the 1st !high #home due fri 9am",
+			want: quickadd.Result{
+				Content:   "pay rent",
+				Frequency: model.Monthly,
+				Due:       at(time.May, 10, 9, 0),
+				Priority:  model.PriorityHigh,
+				Tags:      []string{"home"},
+			},
+		},
+		{
+			input: "standup every weekday at 9:30",
+			want: quickadd.Result{
+				Content:    "standup at 9:30",
+				Frequency:  model.Weekly,
+				Recurrence: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+			},
+		},
+		{
+			input: "water plants every 3 days",
+			want:  quickadd.Result{Content: "water plants", Frequency: model.Daily, Recurrence: "FREQ=DAILY;INTERVAL=3"},
+		},
+		{
+			input: "book club every 2nd tuesday",
+			want:  quickadd.Result{Content: "book club", Frequency: model.Monthly, Recurrence: "FREQ=MONTHLY;BYDAY=2TU"},
+		},
+		{
+			input: "invoice every month on the last day",
+			want:  quickadd.Result{Content: "invoice", Frequency: model.Monthly, Recurrence: "FREQ=MONTHLY;BYMONTHDAY=-1"},
+		},
+		{
+			input: "gym every mon, wed and fri",
+			want:  quickadd.Result{Content: "gym", Frequency: model.Weekly, Recurrence: "FREQ=WEEKLY;BYDAY=MO,WE,FR"},
+		},
+		{
+			input: "review every other week on thu",
+			want:  quickadd.Result{Content: "review", Frequency: model.Weekly, Recurrence: "FREQ=WEEKLY;INTERVAL=2;BYDAY=TH"},
+		},
+		{
+			input: "weekly review every week on monday",
+			want:  quickadd.Result{Content: "weekly review", Frequency: model.Weekly},
+		},
+		{
+			input: "stretch every day 5 times",
+			want:  quickadd.Result{Content: "stretch", Frequency: model.Daily, Recurrence: "FREQ=DAILY;COUNT=5"},
+		},
+		{
+			input: "read every book",
+			want:  quickadd.Result{Content: "read every book"},
+		},
+		{
+			input: "stop by the store",
+			want:  quickadd.Result{Content: "stop by the store"},
+		},
+		{
+			input: "email from bob due tomorrow",
+			want:  quickadd.Result{Content: "email from bob", Due: on(time.May, 9)},
+		},
+		{
+			input: "taxes due 2024-06-15 start in 2 weeks",
+			want:  quickadd.Result{Content: "taxes", Due: on(time.June, 15), Start: on(time.May, 22)},
+		},
+		{
+			input: "call mom by next wed at 6pm",
+			want:  quickadd.Result{Content: "call mom", Due: at(time.May, 15, 18, 0)},
+		},
+		{
+			input: "ship it due 5pm",
+			want:  quickadd.Result{Content: "ship it", Due: at(time.May, 8, 17, 0)},
+		},
+		{
+			input: "renew passport due may 3rd",
+			want:  quickadd.Result{Content: "renew passport", Due: time.Date(2025, time.May, 3, 0, 0, 0, 0, time.Local)},
+		},
+		{
+			input: "plan trip due:+3d start:today +holiday #Travel #travel !urgent",
+			want: quickadd.Result{
+				Content:  "plan trip",
+				Due:      on(time.May, 11),
+				Start:    on(time.May, 8),
+				Priority: model.PriorityUrgent,
+				Tags:     []string{"Travel"},
+				Project:  "holiday",
+			},
+		},
+		{
+			input: "fix issue #42 #bugs",
+			want:  quickadd.Result{Content: "fix issue #42", Tags: []string{"bugs"}},
+		},
+		{
+			input: "call mom #home, then shop",
+			want:  quickadd.Result{Content: "call mom then shop", Tags: []string{"home"}},
+		},
+		{
+			input: "clean /weekly",
+			want:  quickadd.Result{Content: "clean", Frequency: model.Weekly},
+		},
+		{
+			input: "backup RRULE:FREQ=WEEKLY;BYDAY=SU",
+			want:  quickadd.Result{Content: "backup", Frequency: model.Weekly, Recurrence: "FREQ=WEEKLY;BYDAY=SU"},
+		},
+		{
+			input: "say hi! to everyone project:social",
+			want:  quickadd.Result{Content: "say hi! to everyone", Project: "social"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := quickadd.Parse(tt.input, now)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if tt.want.Frequency == "" {
+				tt.want.Frequency = model.Once
+			}
+			if got.Content != tt.want.Content ||
+				got.Frequency != tt.want.Frequency ||
+				got.Recurrence != tt.want.Recurrence ||
+				!got.Due.Equal(tt.want.Due) ||
+				!got.Start.Equal(tt.want.Start) ||
+				got.Priority != tt.want.Priority ||
+				strings.Join(got.Tags, ",") != strings.Join(tt.want.Tags, ",") ||
+				got.Project != tt.want.Project {
+				t.Fatalf("Got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUntil(t *testing.T) {
+	got, err := quickadd.Parse("daily pages every day until jun 1", now)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	rule, err := rrule.Parse(got.Recurrence)
+	if err != nil {
+		t.Fatalf("Recurrence %q: %v", got.Recurrence, err)
+	}
+	if rule.Until.Before(on(time.June, 1)) || !rule.Until.Before(on(time.June, 2)) {
+		t.Fatalf("Until = %v, want during Jun 1", rule.Until)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{
+		"x due:someday",
+		"x start:2024-13-40",
+		"x RRULE:FREQ=HOURLY",
+	} {
+		if _, err := quickadd.Parse(input, now); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", input)
+		}
+	}
+	if _, err := quickadd.Parse("x RRULE:FREQ=NEVER", now); !errors.Is(err, rrule.ErrSyntax) {
+		t.Errorf("Expected RRULE errors to wrap rrule.ErrSyntax, got %v", err)
+	}
+}
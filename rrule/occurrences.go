@@ -0,0 +1,324 @@
+package rrule
+
+import (
+	"sort"
+	"time"
+)
+
+// maxEmptyPeriods stops the search when a rule has not matched for this many
+// periods in a row, e.g. BYMONTHDAY=30 on a yearly rule starting in February.
+const maxEmptyPeriods = 1000
+
+// Each calls fn with every occurrence of the rule in order, until fn returns
+// false or the rule ends. As in RFC 5545 the start time is always the first
+// occurrence, and counts towards COUNT, even if it does not match the rule.
+func (r Rule) Each(start time.Time, fn func(time.Time) bool) {
+	r.each(start, start, fn)
+}
+
+// After returns the first occurrence after t, or at t when inclusive is set.
+// ok is false when the rule ends before that.
+func (r Rule) After(start, t time.Time, inclusive bool) (next time.Time, ok bool) {
+	r.each(start, t, func(occ time.Time) bool {
+		if occ.After(t) || (inclusive && occ.Equal(t)) {
+			next, ok = occ, true
+			return false
+		}
+		return true
+	})
+	return next, ok
+}
+
+// First returns the first occurrence that matches the rule: the start if it
+// does, unlike in Each, otherwise the next one. ok is false when the rule
+// ends before that.
+func (r Rule) First(start time.Time) (first time.Time, ok bool) {
+	for _, d := range r.candidates(start, 0) {
+		if d.Equal(start) {
+			return r.After(start, start, true)
+		}
+	}
+	return r.After(start, start, false)
+}
+
+// Before returns the last occurrence before t, or at t when inclusive is
+// set. ok is false when t is before the start.
+func (r Rule) Before(start, t time.Time, inclusive bool) (prev time.Time, ok bool) {
+	collect := func(occ time.Time) bool {
+		if occ.After(t) || (!inclusive && occ.Equal(t)) {
+			return false
+		}
+		prev, ok = occ, true
+		return true
+	}
+	// The periods skipped to may hold no occurrence before t when the rule
+	// is sparse, so look further back, twice as far each time, until one
+	// turns up or the scan starts at the start.
+	first, step := r.firstPeriod(start, t), max(r.Interval, 1)
+	for {
+		r.eachFrom(start, first, collect)
+		if ok || first == 0 {
+			return prev, ok
+		}
+		first = max(first-step, 0)
+		step *= 2
+	}
+}
+
+// Between returns the occurrences from from to to, both included.
+func (r Rule) Between(start, from, to time.Time) []time.Time {
+	var out []time.Time
+	r.each(start, from, func(occ time.Time) bool {
+		if occ.After(to) {
+			return false
+		}
+		if !occ.Before(from) {
+			out = append(out, occ)
+		}
+		return true
+	})
+	return out
+}
+
+// each is Each, but may skip ahead to shortly before from when no COUNT has
+// to be kept, so that rules started long ago stay cheap. fn still has to
+// ignore occurrences before from.
+func (r Rule) each(start, from time.Time, fn func(time.Time) bool) {
+	r.eachFrom(start, r.firstPeriod(start, from), fn)
+}
+
+// firstPeriod is the period each skips ahead to for from: a multiple of
+// the interval one before the period holding from, or 0.
+func (r Rule) firstPeriod(start, from time.Time) int {
+	interval := max(r.Interval, 1)
+	if r.Count > 0 || !from.After(start) {
+		return 0
+	}
+	return max(r.periodsBetween(start, from.In(start.Location()))/interval-1, 0) * interval
+}
+
+// eachFrom calls fn with the occurrences in periods first and later; the
+// start itself only when first is 0.
+func (r Rule) eachFrom(start time.Time, first int, fn func(time.Time) bool) {
+	interval := max(r.Interval, 1)
+	emitted := 0
+	emit := func(t time.Time) bool {
+		if !r.Until.IsZero() && t.After(r.Until) {
+			return false
+		}
+		if r.Count > 0 && emitted >= r.Count {
+			return false
+		}
+		emitted++
+		return fn(t)
+	}
+
+	if first == 0 && !emit(start) {
+		return
+	}
+
+	empty := 0
+	for k := first; ; k += interval {
+		days := r.candidates(start, k)
+		if len(days) == 0 {
+			if empty++; empty > maxEmptyPeriods {
+				return
+			}
+			continue
+		}
+		empty = 0
+		for _, d := range days {
+			if !d.After(start) {
+				continue
+			}
+			if !emit(d) {
+				return
+			}
+		}
+	}
+}
+
+// periodsBetween counts the whole periods from start to t.
+func (r Rule) periodsBetween(start, t time.Time) int {
+	switch r.Freq {
+	case Daily:
+		return civilDays(start, t)
+	case Weekly:
+		return civilDays(weekStart(start), weekStart(t)) / 7
+	case Monthly:
+		return (t.Year()-start.Year())*12 + int(t.Month()) - int(start.Month())
+	}
+	return t.Year() - start.Year()
+}
+
+// candidates returns the matching days, in order, of the k-th period after
+// the one containing start.
+func (r Rule) candidates(start time.Time, k int) []time.Time {
+	y, m, d := start.Date()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+	}
+
+	switch r.Freq {
+	case Daily:
+		day := at(y, m, d+k)
+		if r.matchesDay(day) {
+			return []time.Time{day}
+		}
+		return nil
+
+	case Weekly:
+		monday := weekStart(start).AddDate(0, 0, 7*k)
+		weekdays := map[time.Weekday]bool{}
+		for _, bd := range r.ByDay {
+			weekdays[bd.Weekday] = true
+		}
+		if len(weekdays) == 0 {
+			weekdays[start.Weekday()] = true
+		}
+		var out []time.Time
+		for i := range 7 {
+			day := at(monday.Year(), monday.Month(), monday.Day()+i)
+			if weekdays[day.Weekday()] {
+				out = append(out, day)
+			}
+		}
+		return out
+
+	case Monthly:
+		first := time.Date(y, m+time.Month(k), 1, 0, 0, 0, 0, time.UTC)
+		return r.monthDays(first.Year(), first.Month(), d, at)
+	}
+
+	// Yearly rules repeat within the month of the start date.
+	return r.monthDays(y+k, m, d, at)
+}
+
+// matchesDay applies BYDAY and BYMONTHDAY as filters, for daily rules.
+func (r Rule) matchesDay(day time.Time) bool {
+	if len(r.ByDay) > 0 {
+		found := false
+		for _, bd := range r.ByDay {
+			found = found || bd.Weekday == day.Weekday()
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(r.ByMonthDay) > 0 {
+		n := daysIn(day.Year(), day.Month())
+		found := false
+		for _, md := range r.ByMonthDay {
+			found = found || resolveMonthDay(md, n) == day.Day()
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// monthDays expands BYDAY and BYMONTHDAY within one month. With neither,
+// the rule falls on the start date's day of the month, and months too short
+// for it are skipped. With both, a day has to match each.
+func (r Rule) monthDays(y int, m time.Month, startDay int, at func(int, time.Month, int) time.Time) []time.Time {
+	n := daysIn(y, m)
+
+	var byMonthDay, byDay map[int]bool
+	if len(r.ByMonthDay) > 0 {
+		byMonthDay = map[int]bool{}
+		for _, md := range r.ByMonthDay {
+			if d := resolveMonthDay(md, n); d > 0 {
+				byMonthDay[d] = true
+			}
+		}
+	}
+	if len(r.ByDay) > 0 {
+		byDay = map[int]bool{}
+		for _, bd := range r.ByDay {
+			for _, d := range weekdaysInMonth(y, m, n, bd) {
+				byDay[d] = true
+			}
+		}
+	}
+
+	var days []int
+	switch {
+	case byMonthDay == nil && byDay == nil:
+		if startDay <= n {
+			days = []int{startDay}
+		}
+	case byDay == nil:
+		for d := range byMonthDay {
+			days = append(days, d)
+		}
+	case byMonthDay == nil:
+		for d := range byDay {
+			days = append(days, d)
+		}
+	default:
+		for d := range byDay {
+			if byMonthDay[d] {
+				days = append(days, d)
+			}
+		}
+	}
+	sort.Ints(days)
+
+	out := make([]time.Time, len(days))
+	for i, d := range days {
+		out[i] = at(y, m, d)
+	}
+	return out
+}
+
+// weekdaysInMonth returns the days of the month matching bd: every such
+// weekday, or only the N-th one.
+func weekdaysInMonth(y int, m time.Month, n int, bd Day) []int {
+	offset := (int(bd.Weekday) - int(time.Date(y, m, 1, 0, 0, 0, 0, time.UTC).Weekday()) + 7) % 7
+	var all []int
+	for d := 1 + offset; d <= n; d += 7 {
+		all = append(all, d)
+	}
+	switch {
+	case bd.N == 0:
+		return all
+	case bd.N > 0 && bd.N <= len(all):
+		return []int{all[bd.N-1]}
+	case bd.N < 0 && -bd.N <= len(all):
+		return []int{all[len(all)+bd.N]}
+	}
+	return nil
+}
+
+// resolveMonthDay turns a BYMONTHDAY value into a day of a month with n
+// days, or 0 if the month has no such day.
+func resolveMonthDay(md, n int) int {
+	if md < 0 {
+		md = n + 1 + md
+	}
+	if md < 1 || md > n {
+		return 0
+	}
+	return md
+}
+
+func daysIn(y int, m time.Month) int {
+	return time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// weekStart is midnight on the Monday of t's week.
+func weekStart(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+}
+
+// civilDays counts calendar days from a to b, ignoring clock time and DST.
+func civilDays(a, b time.Time) int {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	da := time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC)
+	db := time.Date(by, bm, bd, 0, 0, 0, 0, time.UTC)
+	// Unix seconds rather than Sub, which saturates after 292 years.
+	return int((db.Unix() - da.Unix()) / (24 * 60 * 60))
+}
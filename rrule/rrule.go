@@ -0,0 +1,253 @@
+// Package rrule parses the subset of RFC 5545 recurrence rules that todos
+// use and generates their occurrences.
+//
+// Supported parts are FREQ (DAILY, WEEKLY, MONTHLY, YEARLY), INTERVAL,
+// BYDAY (with ordinals such as 2TU or -1FR for MONTHLY and YEARLY),
+// BYMONTHDAY (negative values count from the end of the month), COUNT and
+// UNTIL. Weeks start on Monday, and YEARLY rules repeat within the month of
+// the start date. Occurrences keep the clock time and location of the start
+// time.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Freq is how often a rule repeats.
+type Freq int
+
+const (
+	Daily Freq = iota
+	Weekly
+	Monthly
+	Yearly
+)
+
+var freqNames = []string{"DAILY", "WEEKLY", "MONTHLY", "YEARLY"}
+
+func (f Freq) String() string {
+	if f < 0 || int(f) >= len(freqNames) {
+		return fmt.Sprintf("Freq(%d)", int(f))
+	}
+	return freqNames[f]
+}
+
+// Day is a BYDAY entry: a weekday, optionally the Nth (or Nth from the end,
+// when negative) of that weekday in the month.
+type Day struct {
+	N       int
+	Weekday time.Weekday
+}
+
+var dayNames = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+func (d Day) String() string {
+	if d.N == 0 {
+		return dayNames[d.Weekday]
+	}
+	return strconv.Itoa(d.N) + dayNames[d.Weekday]
+}
+
+// Rule is a parsed recurrence rule.
+type Rule struct {
+	Freq       Freq
+	Interval   int // 1 when not given
+	ByDay      []Day
+	ByMonthDay []int
+	Count      int       // 0 for no limit
+	Until      time.Time // zero for no limit
+}
+
+// ErrSyntax is wrapped by every error Parse returns.
+var ErrSyntax = errors.New("rrule: invalid rule")
+
+// Parse reads a rule such as "FREQ=WEEKLY;BYDAY=MO,WE,FR". A leading
+// "RRULE:" is accepted. UNTIL may be a date (20240131), a UTC time
+// (20240131T235959Z) or a floating local time (20240131T235959).
+func Parse(s string) (Rule, error) {
+	s = strings.TrimSpace(s)
+	if len(s) >= 6 && strings.EqualFold(s[:6], "RRULE:") {
+		s = s[6:]
+	}
+	r := Rule{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || value == "" {
+			return Rule{}, syntaxError("%q is not NAME=VALUE", part)
+		}
+		if seen[name] {
+			return Rule{}, syntaxError("%s given twice", name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			r.Freq, err = parseFreq(value)
+		case "INTERVAL":
+			r.Interval, err = parsePositive(name, value)
+		case "COUNT":
+			r.Count, err = parsePositive(name, value)
+		case "UNTIL":
+			r.Until, err = parseUntil(value)
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseByMonthDay(value)
+		default:
+			err = syntaxError("unsupported part %s", name)
+		}
+		if err != nil {
+			return Rule{}, err
+		}
+	}
+
+	if !seen["FREQ"] {
+		return Rule{}, syntaxError("FREQ is required")
+	}
+	if seen["COUNT"] && seen["UNTIL"] {
+		return Rule{}, syntaxError("COUNT and UNTIL cannot both be given")
+	}
+	if r.Freq == Daily || r.Freq == Weekly {
+		for _, d := range r.ByDay {
+			if d.N != 0 {
+				return Rule{}, syntaxError("BYDAY ordinals need FREQ=MONTHLY or YEARLY")
+			}
+		}
+	}
+	if r.Freq == Weekly && len(r.ByMonthDay) > 0 {
+		return Rule{}, syntaxError("BYMONTHDAY cannot be used with FREQ=WEEKLY")
+	}
+	return r, nil
+}
+
+// MustParse is like Parse but panics on error. It is meant for rules known
+// at compile time.
+func MustParse(s string) Rule {
+	r, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// String formats the rule so that Parse reads it back.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + r.Freq.String()}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = d.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			days[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(utcLayout))
+	}
+	return strings.Join(parts, ";")
+}
+
+const (
+	dateLayout  = "20060102"
+	localLayout = "20060102T150405"
+	utcLayout   = "20060102T150405Z"
+)
+
+func syntaxError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrSyntax, fmt.Sprintf(format, args...))
+}
+
+func parseFreq(v string) (Freq, error) {
+	for i, name := range freqNames {
+		if v == name {
+			return Freq(i), nil
+		}
+	}
+	return 0, syntaxError("unsupported FREQ %s", v)
+}
+
+func parsePositive(name, v string) (int, error) {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, syntaxError("%s must be a positive number, got %s", name, v)
+	}
+	return n, nil
+}
+
+func parseUntil(v string) (time.Time, error) {
+	if t, err := time.Parse(utcLayout, v); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(localLayout, v, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(dateLayout, v, time.Local); err == nil {
+		// A date includes the whole day.
+		return t.AddDate(0, 0, 1).Add(-time.Second), nil
+	}
+	return time.Time{}, syntaxError("UNTIL %s is not a date or time", v)
+}
+
+func parseByDay(v string) ([]Day, error) {
+	var days []Day
+	for _, item := range strings.Split(v, ",") {
+		if len(item) < 2 {
+			return nil, syntaxError("bad BYDAY entry %q", item)
+		}
+		num, name := item[:len(item)-2], item[len(item)-2:]
+		wd := -1
+		for i, n := range dayNames {
+			if n == name {
+				wd = i
+			}
+		}
+		if wd < 0 {
+			return nil, syntaxError("bad weekday in BYDAY entry %q", item)
+		}
+		d := Day{Weekday: time.Weekday(wd)}
+		if num != "" {
+			n, err := strconv.Atoi(num)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, syntaxError("bad ordinal in BYDAY entry %q", item)
+			}
+			d.N = n
+		}
+		days = append(days, d)
+	}
+	return days, nil
+}
+
+func parseByMonthDay(v string) ([]int, error) {
+	var days []int
+	for _, item := range strings.Split(v, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil || n == 0 || n < -31 || n > 31 {
+			return nil, syntaxError("bad BYMONTHDAY entry %q", item)
+		}
+		days = append(days, n)
+	}
+	return days, nil
+}
@@ -0,0 +1,272 @@
+package rrule_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mtix28/noteme/rrule"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 9, 0, 0, 0, time.UTC)
+}
+
+func dates(ts []time.Time) []string {
+	out := make([]string, len(ts))
+	for i, t := range ts {
+		out[i] = t.Format("2006-01-02")
+	}
+	return out
+}
+
+func first(r rrule.Rule, start time.Time, n int) []time.Time {
+	var out []time.Time
+	r.Each(start, func(t time.Time) bool {
+		out = append(out, t)
+		return len(out) < n
+	})
+	return out
+}
+
+func TestParse(t *testing.T) {
+	r, err := rrule.Parse("RRULE:FREQ=MONTHLY;INTERVAL=2;BYDAY=2TU,-1FR;COUNT=5")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if r.Freq != rrule.Monthly || r.Interval != 2 || r.Count != 5 {
+		t.Fatalf("Unexpected rule %+v", r)
+	}
+	want := []rrule.Day{{N: 2, Weekday: time.Tuesday}, {N: -1, Weekday: time.Friday}}
+	if len(r.ByDay) != 2 || r.ByDay[0] != want[0] || r.ByDay[1] != want[1] {
+		t.Fatalf("ByDay = %+v, want %+v", r.ByDay, want)
+	}
+	if got := r.String(); got != "FREQ=MONTHLY;INTERVAL=2;BYDAY=2TU,-1FR;COUNT=5" {
+		t.Fatalf("String = %q", got)
+	}
+}
+
+func TestParseUntil(t *testing.T) {
+	r := rrule.MustParse("FREQ=DAILY;UNTIL=20240105T120000Z")
+	if !r.Until.Equal(time.Date(2024, time.January, 5, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Until = %v", r.Until)
+	}
+	if again := rrule.MustParse(r.String()); !again.Until.Equal(r.Until) {
+		t.Fatalf("Expected UNTIL to round-trip, got %v", again.Until)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=3;UNTIL=20240101",
+		"FREQ=WEEKLY;BYDAY=2MO",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;BYSETPOS=1",
+		"FREQ",
+	} {
+		if _, err := rrule.Parse(s); !errors.Is(err, rrule.ErrSyntax) {
+			t.Errorf("Parse(%q) = %v, want ErrSyntax", s, err)
+		}
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		want  []string
+	}{
+		{
+			name:  "every weekday",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+			start: day(2024, time.May, 3), // Friday
+			want:  []string{"2024-05-03", "2024-05-06", "2024-05-07", "2024-05-08", "2024-05-09", "2024-05-10", "2024-05-13"},
+		},
+		{
+			name:  "every other day",
+			rule:  "FREQ=DAILY;INTERVAL=2",
+			start: day(2024, time.February, 27),
+			want:  []string{"2024-02-27", "2024-02-29", "2024-03-02", "2024-03-04"},
+		},
+		{
+			name:  "second tuesday",
+			rule:  "FREQ=MONTHLY;BYDAY=2TU",
+			start: day(2024, time.January, 9),
+			want:  []string{"2024-01-09", "2024-02-13", "2024-03-12", "2024-04-09"},
+		},
+		{
+			name:  "last day of the month",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			start: day(2024, time.January, 31),
+			want:  []string{"2024-01-31", "2024-02-29", "2024-03-31", "2024-04-30"},
+		},
+		{
+			name:  "last friday",
+			rule:  "FREQ=MONTHLY;BYDAY=-1FR",
+			start: day(2024, time.January, 26),
+			want:  []string{"2024-01-26", "2024-02-23", "2024-03-29"},
+		},
+		{
+			name:  "31st skips short months",
+			rule:  "FREQ=MONTHLY",
+			start: day(2024, time.January, 31),
+			want:  []string{"2024-01-31", "2024-03-31", "2024-05-31", "2024-07-31"},
+		},
+		{
+			name:  "friday the 13th",
+			rule:  "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13",
+			start: day(2024, time.September, 13),
+			want:  []string{"2024-09-13", "2024-12-13", "2025-06-13"},
+		},
+		{
+			name:  "every two weeks",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+			start: day(2024, time.May, 6),
+			want:  []string{"2024-05-06", "2024-05-09", "2024-05-20", "2024-05-23", "2024-06-03"},
+		},
+		{
+			name:  "leap day",
+			rule:  "FREQ=YEARLY",
+			start: day(2024, time.February, 29),
+			want:  []string{"2024-02-29", "2028-02-29", "2032-02-29"},
+		},
+		{
+			name:  "start that does not match counts",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=1;COUNT=3",
+			start: day(2024, time.January, 15),
+			want:  []string{"2024-01-15", "2024-02-01", "2024-03-01"},
+		},
+		{
+			name:  "until",
+			rule:  "FREQ=DAILY;UNTIL=20240103T090000Z",
+			start: day(2024, time.January, 1),
+			want:  []string{"2024-01-01", "2024-01-02", "2024-01-03"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dates(first(rrule.MustParse(tt.rule), tt.start, 10))
+			if len(got) > len(tt.want) {
+				got = got[:len(tt.want)]
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCountLimitsOccurrences(t *testing.T) {
+	got := first(rrule.MustParse("FREQ=DAILY;COUNT=3"), day(2024, time.January, 1), 10)
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 occurrences, got %v", dates(got))
+	}
+}
+
+func TestImpossibleRuleEnds(t *testing.T) {
+	// February never has a 30th.
+	got := first(rrule.MustParse("FREQ=YEARLY;BYMONTHDAY=30"), day(2024, time.February, 1), 10)
+	if len(got) != 1 {
+		t.Fatalf("Expected only the start, got %v", dates(got))
+	}
+}
+
+func TestAfterAndBefore(t *testing.T) {
+	r := rrule.MustParse("FREQ=WEEKLY;BYDAY=MO")
+	start := day(2000, time.January, 3)
+	at := day(2024, time.May, 8) // Wednesday, 24 years of Mondays later
+
+	next, ok := r.After(start, at, false)
+	if !ok || !next.Equal(day(2024, time.May, 13)) {
+		t.Fatalf("After = %v, %v", next, ok)
+	}
+	prev, ok := r.Before(start, at, true)
+	if !ok || !prev.Equal(day(2024, time.May, 6)) {
+		t.Fatalf("Before = %v, %v", prev, ok)
+	}
+	if same, ok := r.After(start, prev, true); !ok || !same.Equal(prev) {
+		t.Fatalf("Inclusive After = %v, %v", same, ok)
+	}
+	if _, ok := r.Before(start, start, false); ok {
+		t.Fatal("Expected nothing before the start")
+	}
+}
+
+func TestBeforeSparseRules(t *testing.T) {
+	for _, c := range []struct {
+		rule      string
+		start, at time.Time
+		want      time.Time
+	}{
+		// Only one day in seven matches a daily rule.
+		{"FREQ=DAILY;BYDAY=MO", day(2026, time.January, 5), day(2026, time.March, 11), day(2026, time.March, 9)},
+		// April has no 31st.
+		{"FREQ=MONTHLY;BYMONTHDAY=31", day(2026, time.January, 31), day(2026, time.May, 15), day(2026, time.March, 31)},
+		{"FREQ=YEARLY;BYMONTHDAY=29", day(2024, time.February, 29), day(2031, time.March, 1), day(2028, time.February, 29)},
+	} {
+		prev, ok := rrule.MustParse(c.rule).Before(c.start, c.at, false)
+		if !ok || !prev.Equal(c.want) {
+			t.Errorf("%s: Before(%v) = %v, %v, want %v", c.rule, c.at, prev, ok, c.want)
+		}
+	}
+}
+
+func TestBetween(t *testing.T) {
+	r := rrule.MustParse("FREQ=MONTHLY;BYMONTHDAY=1,15")
+	got := dates(r.Between(day(2024, time.January, 1), day(2024, time.March, 1), day(2024, time.April, 14)))
+	want := []string{"2024-03-01", "2024-03-15", "2024-04-01"}
+	if len(got) != len(want) {
+		t.Fatalf("Between = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Between = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestKeepsLocalClockAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone not available: %v", err)
+	}
+	start := time.Date(2024, time.March, 9, 8, 0, 0, 0, loc)
+	for _, occ := range first(rrule.MustParse("FREQ=DAILY"), start, 3) {
+		if occ.Hour() != 8 {
+			t.Fatalf("Expected 08:00 local every day, got %v", occ)
+		}
+	}
+}
+
+func TestFirstMatchesTheRule(t *testing.T) {
+	for _, c := range []struct {
+		rule  string
+		start time.Time
+		want  time.Time
+	}{
+		{"FREQ=MONTHLY;BYMONTHDAY=15", day(2024, time.May, 3), day(2024, time.May, 15)},
+		{"FREQ=MONTHLY;BYMONTHDAY=15", day(2024, time.May, 15), day(2024, time.May, 15)},
+		{"FREQ=WEEKLY;BYDAY=MO", day(2024, time.May, 8), day(2024, time.May, 13)},
+		{"FREQ=WEEKLY", day(2024, time.May, 8), day(2024, time.May, 8)},
+		{"FREQ=DAILY", day(2024, time.May, 8), day(2024, time.May, 8)},
+	} {
+		got, ok := rrule.MustParse(c.rule).First(c.start)
+		if !ok || !got.Equal(c.want) {
+			t.Errorf("%s from %v: First = %v, %v, want %v", c.rule, c.start, got, ok, c.want)
+		}
+	}
+}
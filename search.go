@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mtix28/noteme/query"
+	"github.com/mtix28/noteme/search"
+	"github.com/mtix28/noteme/storage"
+)
+
+// searchCommand runs `noteme search [-limit n] QUERY...`, printing the
+// notes and todos that match the query, best first, one per line. Usage
+// goes to stderr when the flags don't parse.
+func searchCommand(store storage.Backend, args []string, out, stderr io.Writer) error {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	limit := fs.Int("limit", 20, "most results to print (0 prints all)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	q, err := query.Parse(strings.Join(fs.Args(), " "), time.Now())
+	if err != nil {
+		return err
+	}
+	if q.Empty() {
+		return errors.New(`give a query, e.g. noteme search folder:work -is:done "exact phrase"`)
+	}
+
+	notes, err := store.LoadNotes()
+	if err != nil {
+		return err
+	}
+	todos, err := store.LoadTodos()
+	if err != nil {
+		return err
+	}
+	projects := map[string]string{}
+	if p, ok := store.(storage.Projects); ok {
+		list, err := p.LoadProjects()
+		if err != nil {
+			return err
+		}
+		for _, project := range list {
+			projects[project.ID] = project.Name
+		}
+	}
+
+	items := make([]query.Item, 0, len(notes)+len(todos))
+	for _, n := range notes {
+		items = append(items, query.Note(n))
+	}
+	for _, t := range todos {
+		items = append(items, query.Todo(t, projects[t.ProjectID]))
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, r := range search.NewIndex(items).Search(q, *limit) {
+		title, where := r.Title, r.Folder
+		if r.Kind == query.KindTodo {
+			title = "[ ] " + title
+			if r.Done {
+				title = "[x] " + r.Title
+			}
+			if r.Project != "" {
+				where = "+" + r.Project
+			}
+		}
+		tags := ""
+		if len(r.Tags) > 0 {
+			tags = "#" + strings.Join(r.Tags, " #")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Kind, r.Created.Format("2006-01-02"), title, where, tags)
+	}
+	return w.Flush()
+}
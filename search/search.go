@@ -0,0 +1,298 @@
+// Package search finds notes and todos matching a query. An Index keeps an
+// inverted index of titles, content, folders and tags in memory and ranks
+// matches by the query's words with BM25.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/mtix28/noteme/query"
+)
+
+// A word in the title counts as much as three in the body, one in the
+// folder or tags as much as two.
+const (
+	titleWeight = 3
+	metaWeight  = 2
+	bodyWeight  = 1
+)
+
+// BM25 parameters: how quickly repeats of a word stop adding to the score,
+// and how much long documents are marked down.
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+type posting struct {
+	doc int
+	tf  float64 // weighted count of the word in the item
+}
+
+// Index is an inverted index over a fixed set of items. Build a new one
+// when they change.
+type Index struct {
+	docs     []query.Item
+	lengths  []float64
+	avgLen   float64
+	postings map[string][]posting
+	terms    []string // the keys of postings, sorted, for prefix lookups
+}
+
+// NewIndex indexes notes and todos.
+func NewIndex(docs []query.Item) *Index {
+	ix := &Index{docs: docs, lengths: make([]float64, len(docs)), postings: map[string][]posting{}}
+	total := 0.0
+	for i, d := range docs {
+		tf := map[string]float64{}
+		count := func(text string, weight float64) {
+			for _, t := range query.Tokenize(text) {
+				tf[t.Word] += weight
+				ix.lengths[i] += weight
+			}
+		}
+		count(d.Title, titleWeight)
+		count(d.Body, bodyWeight)
+		count(d.Folder, metaWeight)
+		count(strings.Join(d.Tags, " "), metaWeight)
+		count(d.Project, metaWeight)
+		for term, n := range tf {
+			ix.postings[term] = append(ix.postings[term], posting{i, n})
+		}
+		total += ix.lengths[i]
+	}
+	if len(docs) > 0 {
+		ix.avgLen = total / float64(len(docs))
+	}
+	ix.terms = make([]string, 0, len(ix.postings))
+	for term := range ix.postings {
+		ix.terms = append(ix.terms, term)
+	}
+	sort.Strings(ix.terms)
+	return ix
+}
+
+// Len is the number of items in the index.
+func (ix *Index) Len() int { return len(ix.docs) }
+
+// Result is an item that matched a search.
+type Result struct {
+	query.Item
+	Score float64
+	Terms []string // the indexed words that matched, for highlighting
+}
+
+// Search returns the items that match q, best first, at most limit of them
+// (all if limit <= 0). They are ranked by the words of the query; each
+// word also counts for longer words it starts, as the query does. Items
+// that only match by their fields rank newest first. An empty query
+// matches nothing.
+func (ix *Index) Search(q *query.Query, limit int) []Result {
+	if q.Empty() {
+		return nil
+	}
+	scores := map[int]float64{}
+	matched := map[int]map[string]bool{}
+	for _, word := range q.Words() {
+		best := map[int]float64{}
+		for _, term := range ix.expand(word) {
+			list := ix.postings[term]
+			idf := math.Log(1 + (float64(len(ix.docs))-float64(len(list))+0.5)/(float64(len(list))+0.5))
+			for _, p := range list {
+				norm := k1 * (1 - b + b*ix.lengths[p.doc]/ix.avgLen)
+				score := idf * p.tf * (k1 + 1) / (p.tf + norm)
+				if term != word {
+					score /= 2 // a longer word is a weaker match than the word itself
+				}
+				best[p.doc] = max(best[p.doc], score)
+				if matched[p.doc] == nil {
+					matched[p.doc] = map[string]bool{}
+				}
+				matched[p.doc][term] = true
+			}
+		}
+		for doc, score := range best {
+			scores[doc] += score
+		}
+	}
+
+	var results []Result
+	for _, i := range ix.candidates(q) {
+		d := ix.docs[i]
+		if !q.Match(d) {
+			continue
+		}
+		terms := make([]string, 0, len(matched[i]))
+		for term := range matched[i] {
+			terms = append(terms, term)
+		}
+		sort.Strings(terms)
+		results = append(results, Result{d, scores[i], terms})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if !results[i].Created.Equal(results[j].Created) {
+			return results[i].Created.After(results[j].Created)
+		}
+		return results[i].Title < results[j].Title
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// candidates lists, in order, the items that can match q: those with the
+// words every match needs, as far as the postings tell. That is all items
+// when q only has field terms or negated words.
+func (ix *Index) candidates(q *query.Query) []int {
+	docs, ok := ix.docsFor(q.Root)
+	if !ok {
+		all := make([]int, len(ix.docs))
+		for i := range all {
+			all[i] = i
+		}
+		return all
+	}
+	list := make([]int, 0, len(docs))
+	for doc := range docs {
+		list = append(list, doc)
+	}
+	sort.Ints(list)
+	return list
+}
+
+// docsFor returns the items the postings allow e to match, or false if
+// they don't narrow it down.
+func (ix *Index) docsFor(e query.Expr) (map[int]bool, bool) {
+	switch e := e.(type) {
+	case query.Word:
+		docs := map[int]bool{}
+		for _, term := range ix.expand(string(e)) {
+			for _, p := range ix.postings[term] {
+				docs[p.doc] = true
+			}
+		}
+		return docs, true
+	case query.Phrase:
+		var exprs query.And
+		for _, w := range e {
+			exprs = append(exprs, query.Word(w))
+		}
+		return ix.docsFor(exprs)
+	case query.Scoped:
+		return ix.docsFor(e.X)
+	case query.And:
+		var docs map[int]bool
+		for _, x := range e {
+			xdocs, ok := ix.docsFor(x)
+			if !ok {
+				continue
+			}
+			if docs == nil {
+				docs = xdocs
+				continue
+			}
+			for doc := range docs {
+				if !xdocs[doc] {
+					delete(docs, doc)
+				}
+			}
+		}
+		return docs, docs != nil
+	case query.Or:
+		docs := map[int]bool{}
+		for _, x := range e {
+			xdocs, ok := ix.docsFor(x)
+			if !ok {
+				return nil, false
+			}
+			for doc := range xdocs {
+				docs[doc] = true
+			}
+		}
+		return docs, true
+	}
+	return nil, false
+}
+
+// expand lists the indexed words that start with prefix.
+func (ix *Index) expand(prefix string) []string {
+	i := sort.SearchStrings(ix.terms, prefix)
+	j := i
+	for j < len(ix.terms) && strings.HasPrefix(ix.terms[j], prefix) {
+		j++
+	}
+	return ix.terms[i:j]
+}
+
+// Span is a byte range of a text.
+type Span struct{ Start, End int }
+
+// Marks finds the words of text that are among terms.
+func Marks(text string, terms []string) []Span {
+	var spans []Span
+	for _, t := range query.Tokenize(text) {
+		if contains(terms, t.Word) {
+			spans = append(spans, Span{t.Start, t.End})
+		}
+	}
+	return spans
+}
+
+// Snippet cuts about width bytes of the body around the first matched word,
+// on one line, along with the matched words in it. A body without a match
+// gives its beginning.
+func (r Result) Snippet(width int) (string, []Span) {
+	body := strings.Join(strings.Fields(r.Body), " ")
+	tokens := query.Tokenize(body)
+	start, first := 0, 0
+	for _, t := range tokens {
+		if contains(r.Terms, t.Word) {
+			// Some context before the match, more if the body ends soon after.
+			start, first = max(min(t.Start-width/4, len(body)-width), 0), t.Start
+			break
+		}
+	}
+	// Start and end on word boundaries.
+	for start > 0 && start < first && body[start-1] != ' ' {
+		start++
+	}
+	end := min(start+width, len(body))
+	for end < len(body) && end > start && body[end] != ' ' {
+		end--
+	}
+	if end == start {
+		end = min(start+width, len(body))
+		for end < len(body) && !isRuneStart(body[end]) {
+			end--
+		}
+	}
+
+	text := body[start:end]
+	prefix := ""
+	if start > 0 {
+		prefix = "…"
+		text = prefix + text
+	}
+	if end < len(body) {
+		text += "…"
+	}
+	spans := Marks(body[start:end], r.Terms)
+	for i := range spans {
+		spans[i].Start += len(prefix)
+		spans[i].End += len(prefix)
+	}
+	return text, spans
+}
+
+func isRuneStart(c byte) bool { return c&0xC0 != 0x80 }
+
+func contains(terms []string, term string) bool {
+	i := sort.SearchStrings(terms, term)
+	return i < len(terms) && terms[i] == term
+}
@@ -0,0 +1,117 @@
+package search_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mtix28/noteme/model"
+	"github.com/mtix28/noteme/query"
+	"github.com/mtix28/noteme/search"
+)
+
+var day = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+var docs = []query.Item{
+	query.Note(model.Note{ID: "n1", Title: "Budget meeting", Content: "Talked about the budget for next year.", Folder: "work", CreatedAt: day}),
+	query.Note(model.Note{ID: "n2", Title: "Groceries", Content: "Milk, eggs and a meeting with the baker.", Folder: "home", CreatedAt: day.AddDate(0, 0, 1)}),
+	query.Note(model.Note{ID: "n3", Title: "Acme", Content: "Kickoff went well.", Folder: "work/clients/acme", CreatedAt: day.AddDate(0, 0, 2)}),
+	query.Todo(model.Todo{ID: "t1", Content: "Prepare budget slides", Tags: []string{"work"}, CreatedAt: day}, "offsite"),
+}
+
+func find(t *testing.T, text string, limit int) []search.Result {
+	t.Helper()
+	q, err := query.Parse(text, day)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", text, err)
+	}
+	return search.NewIndex(docs).Search(q, limit)
+}
+
+func ids(results []search.Result) []string {
+	var out []string
+	for _, r := range results {
+		out = append(out, r.ID)
+	}
+	return out
+}
+
+func TestSearchRanksTitleAboveBody(t *testing.T) {
+	got := ids(find(t, "meeting", 0))
+	if len(got) != 2 || got[0] != "n1" || got[1] != "n2" {
+		t.Fatalf("Search(meeting) = %v, want [n1 n2]", got)
+	}
+}
+
+func TestSearchNeedsEveryWord(t *testing.T) {
+	if got := ids(find(t, "budget slides", 0)); len(got) != 1 || got[0] != "t1" {
+		t.Fatalf("Search(budget slides) = %v, want [t1]", got)
+	}
+	if got := find(t, "budget baker", 0); len(got) != 0 {
+		t.Fatalf("Expected no item with both words, got %v", ids(got))
+	}
+	if got := ids(find(t, "slides OR baker", 0)); len(got) != 2 {
+		t.Fatalf("Search(slides OR baker) = %v, want both", got)
+	}
+}
+
+func TestSearchFoldersTagsAndPrefixes(t *testing.T) {
+	if got := ids(find(t, "clients", 0)); len(got) != 1 || got[0] != "n3" {
+		t.Fatalf("Search(clients) = %v, want [n3]", got)
+	}
+	if got := find(t, "wor", 0); len(got) != 3 {
+		t.Fatalf("Expected wor to match the work folder and tag, got %v", ids(got))
+	}
+	if got := ids(find(t, "offsite", 0)); len(got) != 1 || got[0] != "t1" {
+		t.Fatalf("Expected the project to be searched, got %v", got)
+	}
+	if got := find(t, "", 0); got != nil {
+		t.Fatalf("Expected an empty query to match nothing, got %v", ids(got))
+	}
+	if got := find(t, "budget", 1); len(got) != 1 {
+		t.Fatalf("Expected the limit to apply, got %v", ids(got))
+	}
+}
+
+func TestSearchByFieldsOnly(t *testing.T) {
+	got := ids(find(t, "folder:work", 0))
+	if len(got) != 2 || got[0] != "n3" || got[1] != "n1" {
+		t.Fatalf("Search(folder:work) = %v, want the newest first [n3 n1]", got)
+	}
+	got = ids(find(t, "budget -is:todo", 0))
+	if len(got) != 1 || got[0] != "n1" {
+		t.Fatalf("Search(budget -is:todo) = %v, want [n1]", got)
+	}
+}
+
+func TestSearchMixesWordsAndFields(t *testing.T) {
+	for _, c := range []struct {
+		query string
+		want  int
+	}{
+		{"baker OR folder:work", 3},
+		{"-budget", 2},
+		{"budget (title:slides OR is:note)", 2},
+		{`"next year" budget`, 1},
+		{"meeting -baker", 1},
+	} {
+		if got := find(t, c.query, 0); len(got) != c.want {
+			t.Errorf("Search(%s) = %v, want %d results", c.query, ids(got), c.want)
+		}
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	r := find(t, "baker", 0)[0]
+	text, marks := r.Snippet(30)
+	if text != "…and a meeting with the baker." {
+		t.Fatalf("Snippet = %q", text)
+	}
+	if len(marks) != 1 || text[marks[0].Start:marks[0].End] != "baker" {
+		t.Fatalf("Expected baker marked in %q, got %v", text, marks)
+	}
+
+	title := search.Marks("Budget meeting", r.Terms)
+	if len(title) != 0 {
+		t.Fatalf("Expected nothing marked in an unrelated title, got %v", title)
+	}
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	backupSuffix  = ".bak"
+	corruptSuffix = ".corrupt"
+)
+
+// writeFileAtomic replaces path with data so that readers only ever see the
+// old or the new contents, never a truncated mix. The data goes to a temp
+// file in the same directory, is fsynced, and is then renamed over path.
+// If the current file holds valid JSON it is first copied to path+".bak" so
+// the previous generation survives a bad write.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if current, err := os.ReadFile(path); err == nil && json.Valid(current) {
+		if err := replaceFile(path+backupSuffix, current, perm); err != nil {
+			return fmt.Errorf("rotate backup: %w", err)
+		}
+	}
+	return replaceFile(path, data, perm)
+}
+
+// replaceFile does the temp-file, fsync, rename dance for a single file.
+func replaceFile(path string, data []byte, perm os.FileMode) (err error) {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir flushes the directory entry after a rename. Not every platform
+// supports fsync on directories, so failures are ignored.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
+
+// readJSONFile decodes the versioned data file at path into v, upgrading it
+// in place if it was written in an older format. If the file is not valid
+// JSON, the backup written by writeFileAtomic is used instead: the damaged
+// file is set aside as path+".corrupt" and the backup is restored in its
+// place. Files from a newer version are left alone.
+func readJSONFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	file := filepath.Base(path)
+	version, parseErr := decodeVersioned(file, data, v)
+	if parseErr == nil {
+		if version < SchemaVersion {
+			return upgradeFile(path, data, version, v)
+		}
+		return nil
+	}
+	if errors.Is(parseErr, ErrNewerVersion) {
+		return parseErr
+	}
+
+	backup, err := os.ReadFile(path + backupSuffix)
+	if err != nil {
+		return fmt.Errorf("%s: %w (no usable backup)", file, parseErr)
+	}
+	version, err = decodeVersioned(file, backup, v)
+	if err != nil {
+		return fmt.Errorf("%s: %w (backup is damaged too)", file, parseErr)
+	}
+
+	if err := os.Rename(path, path+corruptSuffix); err != nil {
+		return err
+	}
+	if version < SchemaVersion {
+		return upgradeFile(path, backup, version, v)
+	}
+	return replaceFile(path, backup, 0644)
+}
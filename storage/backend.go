@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"errors"
+
+	"github.com/mtix28/noteme/model"
+)
+
+// ErrNotFound is returned by the Get methods when no item has the given ID.
+var ErrNotFound = errors.New("storage: item not found")
+
+// Backend is the persistence layer used by the UI. Storage is the default
+// JSON-file implementation; MarkdownStorage and SQLiteStorage are the
+// alternatives selectable with --backend, and MemoryStorage keeps everything
+// in memory for tests.
+type Backend interface {
+	LoadNotes() ([]model.Note, error)
+	SaveNotes(notes []model.Note) error
+	UpsertNote(note model.Note) error
+	GetNote(id string) (model.Note, error)
+	DeleteNote(id string) error
+
+	LoadTodos() ([]model.Todo, error)
+	SaveTodos(todos []model.Todo) error
+	UpsertTodo(todo model.Todo) error
+	GetTodo(id string) (model.Todo, error)
+	DeleteTodo(id string) error
+}
+
+// ChangeNotifier is implemented by backends whose data can be changed by
+// other programs, such as MarkdownStorage. Changed reports whether anything
+// was modified since the backend last read or wrote it.
+type ChangeNotifier interface {
+	Changed() (bool, error)
+}
+
+var (
+	_ Backend        = (*Storage)(nil)
+	_ Backend        = (*MemoryStorage)(nil)
+	_ Backend        = (*MarkdownStorage)(nil)
+	_ Backend        = (*SQLiteStorage)(nil)
+	_ ChangeNotifier = (*MarkdownStorage)(nil)
+
+	_ History = (*Storage)(nil)
+	_ History = (*MemoryStorage)(nil)
+	_ History = (*MarkdownStorage)(nil)
+	_ History = (*SQLiteStorage)(nil)
+
+	_ Trash = (*Storage)(nil)
+	_ Trash = (*MemoryStorage)(nil)
+	_ Trash = (*MarkdownStorage)(nil)
+	_ Trash = (*SQLiteStorage)(nil)
+
+	_ Projects = (*Storage)(nil)
+	_ Projects = (*MemoryStorage)(nil)
+	_ Projects = (*MarkdownStorage)(nil)
+	_ Projects = (*SQLiteStorage)(nil)
+
+	_ SavedSearches = (*Storage)(nil)
+	_ SavedSearches = (*MemoryStorage)(nil)
+	_ SavedSearches = (*MarkdownStorage)(nil)
+	_ SavedSearches = (*SQLiteStorage)(nil)
+
+	_ Versioned = (*Storage)(nil)
+	_ Versioned = (*MemoryStorage)(nil)
+	_ Versioned = (*MarkdownStorage)(nil)
+	_ Versioned = (*SQLiteStorage)(nil)
+)
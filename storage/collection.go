@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// A collection is one JSON snapshot file (notes.json) plus its journal
+// (notes.log). These helpers hold the logic shared by every collection the
+// Storage keeps; callers hold the lock.
+
+// hasJSONData reports whether the collection file in dir has been written:
+// a snapshot, a journal or both.
+func hasJSONData(dir, file string) bool {
+	path := filepath.Join(dir, file)
+	return fileExists(path) || fileExists(journalFor(path))
+}
+
+// loadCollection reads the snapshot of file and replays its journal on top.
+// A collection that does not exist yet is empty.
+func loadCollection[T any](s *Storage, file string, idOf func(T) string) ([]T, error) {
+	path := filepath.Join(s.basePath, file)
+	var items []T
+	if err := readJSONFile(path, &items); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	entries, err := readJournal[T](journalFor(path))
+	if err != nil {
+		return nil, err
+	}
+	return applyJournal(items, entries, idOf), nil
+}
+
+// saveCollection replaces the snapshot of file with items and drops the
+// journal, which items already account for.
+func saveCollection[T any](s *Storage, file string, items []T) error {
+	path := filepath.Join(s.basePath, file)
+	if err := checkVersion(path); err != nil {
+		return err
+	}
+	data, err := encodeVersioned(items)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return err
+	}
+	// The snapshot now includes everything the journal held.
+	if err := os.Remove(journalFor(path)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return s.rememberETag(file)
+}
+
+// compact returns the compaction appendEntry runs for a collection: the
+// journal of file folded into its snapshot.
+func compact[T any](s *Storage, file string, idOf func(T) string) func() error {
+	return func() error {
+		items, err := loadCollection(s, file, idOf)
+		if err != nil {
+			return err
+		}
+		return saveCollection(s, file, items)
+	}
+}
+
+// appendEntry journals one change to the collection stored in file and
+// compacts the journal once it grows too big. Callers hold the lock.
+func appendEntry[T any](s *Storage, file string, e journalEntry[T], compact func() error) error {
+	// Only move our revision forward if nobody else wrote in between;
+	// otherwise a later SaveNotes/SaveTodos must still see the conflict.
+	upToDate := s.checkETag(file) == nil
+
+	path := filepath.Join(s.basePath, file)
+	if err := checkVersion(path); err != nil {
+		return err
+	}
+	logPath := journalFor(path)
+	if err := appendJournal(logPath, e); err != nil {
+		return err
+	}
+	if journalTooBig(logPath) {
+		seen := s.etags[file]
+		if err := compact(); err != nil {
+			return err
+		}
+		if !upToDate {
+			s.etags[file] = seen
+		}
+		return nil
+	}
+	if upToDate {
+		return s.rememberETag(file)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const (
+	// AppName is the directory name used under $XDG_DATA_HOME.
+	AppName = "noteme"
+	// EnvDataDir overrides the data directory when set.
+	EnvDataDir = "NOTEME_DIR"
+)
+
+// DataDir resolves the directory noteme keeps its files in. The first
+// non-empty source wins:
+//
+//  1. flagValue (the --data-dir flag)
+//  2. $NOTEME_DIR
+//  3. ~/.noteme, the historical location, if it exists, so that setting
+//     $XDG_DATA_HOME does not hide the data of an existing install
+//  4. $XDG_DATA_HOME/noteme
+//  5. ~/.noteme
+func DataDir(flagValue string) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+	if dir := os.Getenv(EnvDataDir); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	legacy := filepath.Join(home, DirName)
+	if info, err := os.Stat(legacy); err == nil && info.IsDir() {
+		return legacy, nil
+	}
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		return filepath.Join(xdg, AppName), nil
+	}
+	return legacy, nil
+}
+
+// HasData reports whether dir holds anything a backend has saved: JSON
+// collections, Markdown notes or a SQLite database. Opening a store in a
+// directory without data seeds it with the welcome note and todo.
+func HasData(dir string) bool {
+	return hasJSONData(dir, NotesFile) || hasJSONData(dir, TodosFile) ||
+		fileExists(filepath.Join(dir, NotesDir)) || fileExists(filepath.Join(dir, DatabaseFile))
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"sort"
+
+	"github.com/mtix28/noteme/model"
+)
+
+// RevisionsFile holds the revision history of every note.
+const RevisionsFile = "revisions.json"
+
+// History is implemented by backends that keep past versions of notes.
+type History interface {
+	// AddRevision records one saved version of a note.
+	AddRevision(rev model.Revision) error
+	// Revisions returns the versions of a note, newest first.
+	Revisions(noteID string) ([]model.Revision, error)
+	// DeleteRevisions drops every version of a note, once it is gone for
+	// good.
+	DeleteRevisions(noteID string) error
+}
+
+func (s *Storage) AddRevision(rev model.Revision) error {
+	return s.withLock(func() error {
+		return appendEntry(s, RevisionsFile, journalEntry[model.Revision]{Op: opPut, ID: rev.ID, Item: &rev}, compact(s, RevisionsFile, revisionID))
+	})
+}
+
+func (s *Storage) Revisions(noteID string) ([]model.Revision, error) {
+	var revs []model.Revision
+	err := s.withLock(func() error {
+		all, err := loadCollection(s, RevisionsFile, revisionID)
+		if err != nil {
+			return err
+		}
+		revs = revisionsOf(all, noteID)
+		return nil
+	})
+	return revs, err
+}
+
+func (s *Storage) DeleteRevisions(noteID string) error {
+	return s.withLock(func() error {
+		return s.deleteRevisions(noteID)
+	})
+}
+
+// deleteRevisions drops the revisions of the given notes. Callers hold the
+// lock.
+func (s *Storage) deleteRevisions(noteIDs ...string) error {
+	if len(noteIDs) == 0 {
+		return nil
+	}
+	all, err := loadCollection(s, RevisionsFile, revisionID)
+	if err != nil {
+		return err
+	}
+	kept := withoutRevisionsOf(all, noteIDs)
+	if len(kept) == len(all) {
+		return nil
+	}
+	return saveCollection(s, RevisionsFile, kept)
+}
+
+func revisionID(r model.Revision) string { return r.ID }
+
+// withoutRevisionsOf leaves the revisions of the given notes out of all.
+func withoutRevisionsOf(all []model.Revision, noteIDs []string) []model.Revision {
+	drop := map[string]bool{}
+	for _, id := range noteIDs {
+		drop[id] = true
+	}
+	var kept []model.Revision
+	for _, r := range all {
+		if !drop[r.NoteID] {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// revisionsOf picks the revisions of one note out of all, newest first.
+func revisionsOf(all []model.Revision, noteID string) []model.Revision {
+	var revs []model.Revision
+	for _, r := range all {
+		if r.NoteID == noteID {
+			revs = append(revs, r)
+		}
+	}
+	sort.SliceStable(revs, func(i, j int) bool {
+		return revs[i].SavedAt.After(revs[j].SavedAt)
+	})
+	return revs
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Single-item changes are not written into notes.json/todos.json directly.
+// They are appended to a journal next to the snapshot (notes.log, todos.log),
+// one JSON object per line, and replayed on load. Once the journal grows past
+// compactSize it is folded back into the snapshot and removed.
+const (
+	NotesLog = "notes.log"
+	TodosLog = "todos.log"
+
+	compactSize = 256 << 10
+)
+
+const (
+	opPut = "put"
+	opDel = "del"
+)
+
+type journalEntry[T any] struct {
+	Op   string `json:"op"`
+	ID   string `json:"id"`
+	Item *T     `json:"item,omitempty"`
+}
+
+// journalFor maps a snapshot file to its journal, e.g. notes.json -> notes.log.
+func journalFor(file string) string {
+	return strings.TrimSuffix(file, filepath.Ext(file)) + ".log"
+}
+
+// appendJournal writes one entry and fsyncs it before returning.
+func appendJournal[T any](path string, e journalEntry[T]) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readJournal returns the entries in path in the order they were written. A
+// missing journal is empty. A damaged last line is what a crash mid-append
+// leaves behind, so it is dropped; damage anywhere else is an error.
+func readJournal[T any](path string) ([]journalEntry[T], error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lines := bytes.Split(data, []byte("\n"))
+	var entries []journalEntry[T]
+	for i, raw := range lines {
+		if len(bytes.TrimSpace(raw)) == 0 {
+			continue
+		}
+		var e journalEntry[T]
+		if err := json.Unmarshal(raw, &e); err != nil {
+			if i == len(lines)-1 {
+				break // torn final append
+			}
+			return nil, fmt.Errorf("%s line %d: %w", filepath.Base(path), i+1, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// applyJournal replays entries over items. Puts replace the item with the
+// same ID in place, or prepend it if it is new, matching the newest-first
+// order the UI keeps.
+func applyJournal[T any](items []T, entries []journalEntry[T], idOf func(T) string) []T {
+	for _, e := range entries {
+		idx := -1
+		for i, it := range items {
+			if idOf(it) == e.ID {
+				idx = i
+				break
+			}
+		}
+		switch {
+		case e.Op == opPut && e.Item != nil && idx >= 0:
+			items[idx] = *e.Item
+		case e.Op == opPut && e.Item != nil:
+			items = append([]T{*e.Item}, items...)
+		case e.Op == opDel && idx >= 0:
+			items = append(items[:idx:idx], items[idx+1:]...)
+		}
+	}
+	return items
+}
+
+// journalTooBig reports whether the journal at path is due for compaction.
+func journalTooBig(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Size() > compactSize
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
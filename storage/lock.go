@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// LockFile is the advisory lock every Storage operation holds, so two noteme
+// processes sharing a data directory take turns instead of interleaving.
+const LockFile = ".lock"
+
+// ErrConflict is returned by SaveNotes/SaveTodos when the file on disk has
+// changed since this Storage last read or wrote it. Reload, re-apply the
+// change and save again. The Versioned upserts return it when another writer
+// changed the same fields of the item.
+var ErrConflict = errors.New("storage: file was modified by another writer")
+
+// withLock runs fn while holding both the in-process mutex and the
+// cross-process file lock.
+func (s *Storage) withLock(fn func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(filepath.Join(s.basePath, LockFile), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := lockFile(f); err != nil {
+		return err
+	}
+	defer unlockFile(f)
+
+	return fn()
+}
+
+// etagOf returns the revision tag of a collection: its snapshot file plus
+// the journal of changes appended since. A missing collection has the empty
+// tag.
+func etagOf(path string) (string, error) {
+	h := sha256.New()
+	seen := false
+	for _, p := range []string{path, journalFor(path)} {
+		data, err := os.ReadFile(p)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		h.Write(data)
+		seen = true
+	}
+	if !seen {
+		return "", nil
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8]), nil
+}
+
+// checkETag fails with ErrConflict if file no longer matches the revision
+// this Storage last saw. Files that were never read are not checked.
+func (s *Storage) checkETag(file string) error {
+	seen, ok := s.etags[file]
+	if !ok {
+		return nil
+	}
+	current, err := etagOf(filepath.Join(s.basePath, file))
+	if err != nil {
+		return err
+	}
+	if current != seen {
+		return ErrConflict
+	}
+	return nil
+}
+
+// rememberETag records the current revision of file after a read or write.
+func (s *Storage) rememberETag(file string) error {
+	tag, err := etagOf(filepath.Join(s.basePath, file))
+	if err != nil {
+		return err
+	}
+	s.etags[file] = tag
+	return nil
+}
+
+// ETag returns the revision of file as of the last load or save through this
+// Storage, or "" if it has not been touched yet.
+func (s *Storage) ETag(file string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.etags[file]
+}
@@ -0,0 +1,10 @@
+//go:build !unix && !windows
+
+package storage
+
+import "os"
+
+// Advisory locks are not available here; the in-process mutex in Storage is
+// the only protection.
+func lockFile(f *os.File) error   { return nil }
+func unlockFile(f *os.File) error { return nil }
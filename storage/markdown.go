@@ -0,0 +1,452 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mtix28/noteme/model"
+	"gopkg.in/yaml.v3"
+)
+
+// NotesDir is the directory, inside the data directory, that
+// MarkdownStorage keeps its .md files in.
+const NotesDir = "notes"
+
+// MarkdownStorage keeps each note as <folder>/<slug>.md with YAML front
+// matter, so the notes can be grepped, diffed and edited with other tools.
+// Todos are not a good fit for loose files and stay in todos.json through
+// the embedded Storage.
+//
+// Files that show up without front matter (created in another editor) are
+// picked up too: their title comes from the file name, their folder from the
+// directory and their ID from the path, until noteme saves them once.
+type MarkdownStorage struct {
+	*Storage
+	notesDir string
+
+	paths map[string]string // note ID -> path relative to notesDir
+	stamp string            // fingerprint of the tree as of the last read or write
+}
+
+type frontMatter struct {
+	ID        string    `yaml:"id"`
+	Title     string    `yaml:"title"`
+	CreatedAt time.Time `yaml:"created_at"`
+	Folder    string    `yaml:"folder"`
+	Tags      tagList   `yaml:"tags,omitempty"`
+}
+
+// tagList reads tags written either as a YAML list or, as some editors do,
+// as one string separated by spaces or commas.
+type tagList []string
+
+func (t *tagList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*t = strings.FieldsFunc(value.Value, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+		return nil
+	}
+	var tags []string
+	if err := value.Decode(&tags); err != nil {
+		return err
+	}
+	*t = tags
+	return nil
+}
+
+var frontMatterFence = []byte("---\n")
+
+// NewMarkdownStorageAt opens a Markdown store rooted at the data directory
+// path. Notes live in path/notes, todos in path/todos.json.
+func NewMarkdownStorageAt(path string) (*MarkdownStorage, error) {
+	base, err := NewStorageAt(path)
+	if err != nil {
+		return nil, err
+	}
+	return &MarkdownStorage{Storage: base, notesDir: filepath.Join(path, NotesDir)}, nil
+}
+
+func (s *MarkdownStorage) LoadNotes() ([]model.Note, error) {
+	var notes []model.Note
+	err := s.withLock(func() (err error) {
+		notes, err = s.loadNotes()
+		return err
+	})
+	return notes, err
+}
+
+// SaveNotes makes the notes directory hold exactly notes: every note is
+// written and .md files belonging to other notes are removed.
+func (s *MarkdownStorage) SaveNotes(notes []model.Note) error {
+	return s.withLock(func() error {
+		if _, err := s.loadNotes(); err != nil {
+			return err
+		}
+		keep := make(map[string]bool, len(notes))
+		for _, n := range notes {
+			if err := s.writeNote(n); err != nil {
+				return err
+			}
+			keep[n.ID] = true
+		}
+		for id := range s.paths {
+			if !keep[id] {
+				if err := s.removeNote(id); err != nil {
+					return err
+				}
+			}
+		}
+		return s.restamp()
+	})
+}
+
+func (s *MarkdownStorage) UpsertNote(note model.Note) error {
+	return s.withLock(func() error {
+		if s.paths == nil {
+			if _, err := s.loadNotes(); err != nil {
+				return err
+			}
+		}
+		if err := s.writeNote(note); err != nil {
+			return err
+		}
+		return s.restamp()
+	})
+}
+
+// UpsertNoteFrom writes the note's file like UpsertNote, merged with any
+// change made to it since base was loaded; see Versioned.
+func (s *MarkdownStorage) UpsertNoteFrom(base, note model.Note) (merged bool, err error) {
+	err = s.withLock(func() error {
+		notes, err := s.loadNotes()
+		if err != nil {
+			return err
+		}
+		stored, found := findByID(notes, note.ID, func(n model.Note) string { return n.ID })
+		if note, merged, err = rebase(base, note, stored, found); err != nil {
+			return err
+		}
+		if err := s.writeNote(note); err != nil {
+			return err
+		}
+		return s.restamp()
+	})
+	return merged, err
+}
+
+func (s *MarkdownStorage) GetNote(id string) (model.Note, error) {
+	var found model.Note
+	err := s.withLock(func() error {
+		notes, err := s.loadNotes()
+		if err != nil {
+			return err
+		}
+		for _, n := range notes {
+			if n.ID == id {
+				found = n
+				return nil
+			}
+		}
+		return ErrNotFound
+	})
+	return found, err
+}
+
+// DeleteNote moves the note's file into the trash collection.
+func (s *MarkdownStorage) DeleteNote(id string) error {
+	return s.withLock(func() error {
+		notes, err := s.loadNotes()
+		if err != nil {
+			return err
+		}
+		for _, n := range notes {
+			if n.ID != id {
+				continue
+			}
+			if err := s.moveToTrash(model.TrashedNote(n, time.Now())); err != nil {
+				return err
+			}
+			if err := s.removeNote(id); err != nil {
+				return err
+			}
+			return s.restamp()
+		}
+		return nil
+	})
+}
+
+// RestoreItem puts trashed notes back as .md files; todos go back to
+// todos.json as usual.
+func (s *MarkdownStorage) RestoreItem(id string) error {
+	return s.withLock(func() error {
+		item, err := s.takeFromTrash(id)
+		if err != nil {
+			return err
+		}
+		if item.Todo != nil {
+			return appendEntry(s.Storage, TodosFile, journalEntry[model.Todo]{Op: opPut, ID: item.ID, Item: item.Todo}, s.compactTodos)
+		}
+		if s.paths == nil {
+			if _, err := s.loadNotes(); err != nil {
+				return err
+			}
+		}
+		if err := s.writeNote(*item.Note); err != nil {
+			return err
+		}
+		return s.restamp()
+	})
+}
+
+// Changed reports whether any .md file was added, removed or modified since
+// the last time this store read or wrote the notes directory.
+func (s *MarkdownStorage) Changed() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stamp, err := s.fingerprint()
+	if err != nil {
+		return false, err
+	}
+	return stamp != s.stamp, nil
+}
+
+func (s *MarkdownStorage) loadNotes() ([]model.Note, error) {
+	if _, err := os.Stat(s.notesDir); errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(s.notesDir, 0755); err != nil {
+			return nil, err
+		}
+		// Seed the same welcome note the JSON store starts with.
+		s.paths = map[string]string{}
+		if err := s.writeNote(welcomeNote()); err != nil {
+			return nil, err
+		}
+	}
+
+	var notes []model.Note
+	paths := map[string]string{}
+	err := filepath.WalkDir(s.notesDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(p) != ".md" {
+			return nil
+		}
+		rel, err := filepath.Rel(s.notesDir, p)
+		if err != nil {
+			return err
+		}
+		n, err := readNoteFile(p, filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		if _, dup := paths[n.ID]; dup {
+			// A copied file still carries its original ID; tell them apart.
+			n.ID = pathID(filepath.ToSlash(rel))
+		}
+		paths[n.ID] = filepath.ToSlash(rel)
+		notes = append(notes, n)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(notes, func(i, j int) bool {
+		return notes[i].CreatedAt.After(notes[j].CreatedAt)
+	})
+	s.paths = paths
+	return notes, s.restamp()
+}
+
+// readNoteFile parses one .md file. rel is its slash-separated path below
+// the notes directory.
+func readNoteFile(p, rel string) (model.Note, error) {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return model.Note{}, err
+	}
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+
+	var fm frontMatter
+	body := data
+	if bytes.HasPrefix(data, frontMatterFence) {
+		rest := data[len(frontMatterFence):]
+		end := bytes.Index(rest, []byte("\n---\n"))
+		if end < 0 && bytes.HasSuffix(rest, []byte("\n---")) {
+			end = len(rest) - len("\n---")
+		}
+		if end >= 0 {
+			if err := yaml.Unmarshal(rest[:end+1], &fm); err != nil {
+				return model.Note{}, fmt.Errorf("%s: front matter: %w", rel, err)
+			}
+			body = rest[min(end+len("\n---\n"), len(rest)):]
+		}
+	}
+
+	folder := path.Dir(rel)
+	if folder == "." {
+		folder = ""
+	}
+	if fm.ID == "" {
+		fm.ID = pathID(rel)
+	}
+	if fm.Title == "" {
+		fm.Title = strings.TrimSuffix(path.Base(rel), ".md")
+	}
+	if fm.CreatedAt.IsZero() {
+		if info, err := os.Stat(p); err == nil {
+			fm.CreatedAt = info.ModTime()
+		}
+	}
+
+	return model.Note{
+		ID:        fm.ID,
+		Title:     fm.Title,
+		Content:   string(body),
+		CreatedAt: fm.CreatedAt,
+		Tags:      model.NormalizeTags(fm.Tags),
+		// The directory wins over the front matter so that moving a file
+		// in a file manager moves the note.
+		Folder: folder,
+	}, nil
+}
+
+// writeNote writes n to <folder>/<slug>.md, removing the file it used to
+// live in if its title or folder changed. Callers hold the lock.
+func (s *MarkdownStorage) writeNote(n model.Note) error {
+	folder := model.CleanFolder(n.Folder)
+	n.Folder = folder
+
+	old, hadOld := s.paths[n.ID]
+	rel := s.freePath(n, folder)
+
+	fm, err := yaml.Marshal(frontMatter{ID: n.ID, Title: n.Title, CreatedAt: n.CreatedAt, Folder: n.Folder, Tags: n.Tags})
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	buf.Write(frontMatterFence)
+	buf.Write(fm)
+	buf.Write(frontMatterFence)
+	buf.WriteString(n.Content)
+
+	abs := filepath.Join(s.notesDir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(abs), 0755); err != nil {
+		return err
+	}
+	if err := replaceFile(abs, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	if hadOld && old != rel {
+		if err := os.Remove(filepath.Join(s.notesDir, filepath.FromSlash(old))); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	s.paths[n.ID] = rel
+	return nil
+}
+
+// freePath picks the file for n: folder/slug.md, or slug-2.md, slug-3.md ...
+// when another note already uses that name.
+func (s *MarkdownStorage) freePath(n model.Note, folder string) string {
+	taken := map[string]bool{}
+	for id, p := range s.paths {
+		if id != n.ID {
+			taken[p] = true
+		}
+	}
+	slug := slugify(n.Title)
+	for i := 1; ; i++ {
+		name := slug + ".md"
+		if i > 1 {
+			name = fmt.Sprintf("%s-%d.md", slug, i)
+		}
+		rel := path.Join(folder, name)
+		if !taken[rel] {
+			return rel
+		}
+	}
+}
+
+func (s *MarkdownStorage) removeNote(id string) error {
+	rel, ok := s.paths[id]
+	if !ok {
+		return nil
+	}
+	if err := os.Remove(filepath.Join(s.notesDir, filepath.FromSlash(rel))); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	delete(s.paths, id)
+	return nil
+}
+
+func (s *MarkdownStorage) restamp() error {
+	stamp, err := s.fingerprint()
+	if err != nil {
+		return err
+	}
+	s.stamp = stamp
+	return nil
+}
+
+// fingerprint summarises the name, size and modification time of every .md
+// file without reading their contents.
+func (s *MarkdownStorage) fingerprint() (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(s.notesDir, func(p string, d fs.DirEntry, err error) error {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(p) != ".md" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", p, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// slugify makes a file name out of a note title.
+func slugify(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	slug := strings.TrimSuffix(b.String(), "-")
+	if slug == "" {
+		return "untitled"
+	}
+	return slug
+}
+
+// pathID is the ID given to files that have none in their front matter.
+func pathID(rel string) string {
+	return "file:" + rel
+}
@@ -0,0 +1,294 @@
+package storage
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mtix28/noteme/model"
+)
+
+// MemoryStorage is a Backend that never touches the disk. It starts empty
+// (no welcome items are seeded) and hands out copies, so callers cannot
+// mutate its state behind its back.
+type MemoryStorage struct {
+	mu        sync.Mutex
+	notes     []model.Note
+	todos     []model.Todo
+	revisions []model.Revision
+	trash     []model.TrashItem
+	projects  []model.Project
+	searches  []model.SavedSearch
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{}
+}
+
+func (s *MemoryStorage) LoadNotes() ([]model.Note, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]model.Note(nil), s.notes...), nil
+}
+
+func (s *MemoryStorage) SaveNotes(notes []model.Note) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notes = append([]model.Note(nil), notes...)
+	return nil
+}
+
+func (s *MemoryStorage) UpsertNote(note model.Note) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, n := range s.notes {
+		if n.ID == note.ID {
+			s.notes[i] = note
+			return nil
+		}
+	}
+	s.notes = append([]model.Note{note}, s.notes...)
+	return nil
+}
+
+func (s *MemoryStorage) UpsertNoteFrom(base, note model.Note) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, found := findByID(s.notes, note.ID, func(n model.Note) string { return n.ID })
+	note, merged, err := rebase(base, note, stored, found)
+	if err != nil {
+		return false, err
+	}
+	for i, n := range s.notes {
+		if n.ID == note.ID {
+			s.notes[i] = note
+			return merged, nil
+		}
+	}
+	s.notes = append([]model.Note{note}, s.notes...)
+	return merged, nil
+}
+
+func (s *MemoryStorage) GetNote(id string) (model.Note, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, n := range s.notes {
+		if n.ID == id {
+			return n, nil
+		}
+	}
+	return model.Note{}, ErrNotFound
+}
+
+func (s *MemoryStorage) DeleteNote(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, n := range s.notes {
+		if n.ID == id {
+			s.trash = append(s.trash, model.TrashedNote(n, time.Now()))
+			s.notes = append(s.notes[:i:i], s.notes[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStorage) LoadTodos() ([]model.Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]model.Todo(nil), s.todos...), nil
+}
+
+func (s *MemoryStorage) SaveTodos(todos []model.Todo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.todos = append([]model.Todo(nil), todos...)
+	return nil
+}
+
+func (s *MemoryStorage) UpsertTodo(todo model.Todo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, t := range s.todos {
+		if t.ID == todo.ID {
+			s.todos[i] = todo
+			return nil
+		}
+	}
+	s.todos = append([]model.Todo{todo}, s.todos...)
+	return nil
+}
+
+func (s *MemoryStorage) UpsertTodoFrom(base, todo model.Todo) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, found := findByID(s.todos, todo.ID, func(t model.Todo) string { return t.ID })
+	todo, merged, err := rebase(base, todo, stored, found)
+	if err != nil {
+		return false, err
+	}
+	for i, t := range s.todos {
+		if t.ID == todo.ID {
+			s.todos[i] = todo
+			return merged, nil
+		}
+	}
+	s.todos = append([]model.Todo{todo}, s.todos...)
+	return merged, nil
+}
+
+func (s *MemoryStorage) GetTodo(id string) (model.Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.todos {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return model.Todo{}, ErrNotFound
+}
+
+func (s *MemoryStorage) DeleteTodo(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, t := range s.todos {
+		if t.ID == id {
+			s.trash = append(s.trash, model.TrashedTodo(t, time.Now()))
+			s.todos = append(s.todos[:i:i], s.todos[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStorage) AddRevision(rev model.Revision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revisions = append(s.revisions, rev)
+	return nil
+}
+
+func (s *MemoryStorage) Revisions(noteID string) ([]model.Revision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return revisionsOf(s.revisions, noteID), nil
+}
+
+func (s *MemoryStorage) DeleteRevisions(noteID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revisions = withoutRevisionsOf(s.revisions, []string{noteID})
+	return nil
+}
+
+func (s *MemoryStorage) LoadProjects() ([]model.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]model.Project(nil), s.projects...), nil
+}
+
+func (s *MemoryStorage) UpsertProject(p model.Project) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.projects {
+		if existing.ID == p.ID {
+			s.projects[i] = p
+			return nil
+		}
+	}
+	s.projects = append(s.projects, p)
+	return nil
+}
+
+func (s *MemoryStorage) DeleteProject(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, p := range s.projects {
+		if p.ID == id {
+			s.projects = append(s.projects[:i:i], s.projects[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (s *MemoryStorage) LoadSavedSearches() ([]model.SavedSearch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]model.SavedSearch(nil), s.searches...), nil
+}
+
+func (s *MemoryStorage) UpsertSavedSearch(saved model.SavedSearch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.searches {
+		if existing.ID == saved.ID {
+			s.searches[i] = saved
+			return nil
+		}
+	}
+	s.searches = append(s.searches, saved)
+	return nil
+}
+
+func (s *MemoryStorage) DeleteSavedSearch(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, saved := range s.searches {
+		if saved.ID == id {
+			s.searches = append(s.searches[:i:i], s.searches[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (s *MemoryStorage) TrashedItems() ([]model.TrashItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := append([]model.TrashItem(nil), s.trash...)
+	sortTrash(items)
+	return items, nil
+}
+
+func (s *MemoryStorage) RestoreItem(id string) error {
+	s.mu.Lock()
+	item, err := s.takeFromTrash(id)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if item.Note != nil {
+		return s.UpsertNote(*item.Note)
+	}
+	return s.UpsertTodo(*item.Todo)
+}
+
+func (s *MemoryStorage) PurgeItem(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, err := s.takeFromTrash(id)
+	if err != nil {
+		return err
+	}
+	s.revisions = withoutRevisionsOf(s.revisions, notesIn([]model.TrashItem{item}))
+	return nil
+}
+
+func (s *MemoryStorage) PurgeTrash(cutoff time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept, expired := splitExpired(s.trash, cutoff)
+	s.trash = kept
+	s.revisions = withoutRevisionsOf(s.revisions, notesIn(expired))
+	return len(expired), nil
+}
+
+func (s *MemoryStorage) takeFromTrash(id string) (model.TrashItem, error) {
+	for i, item := range s.trash {
+		if item.ID == id {
+			s.trash = append(s.trash[:i:i], s.trash[i+1:]...)
+			return item, nil
+		}
+	}
+	return model.TrashItem{}, ErrNotFound
+}
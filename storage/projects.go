@@ -0,0 +1,46 @@
+package storage
+
+import "github.com/mtix28/noteme/model"
+
+// ProjectsFile holds the projects todos can belong to.
+const ProjectsFile = "projects.json"
+
+// Projects is implemented by backends that store projects. Deleting a
+// project does not touch its todos.
+type Projects interface {
+	LoadProjects() ([]model.Project, error)
+	UpsertProject(p model.Project) error
+	DeleteProject(id string) error
+}
+
+func (s *Storage) LoadProjects() ([]model.Project, error) {
+	var projects []model.Project
+	err := s.withLock(func() (err error) {
+		projects, err = loadCollection(s, ProjectsFile, projectID)
+		return err
+	})
+	return projects, err
+}
+
+func (s *Storage) UpsertProject(p model.Project) error {
+	return s.withLock(func() error {
+		return appendEntry(s, ProjectsFile, journalEntry[model.Project]{Op: opPut, ID: p.ID, Item: &p}, compact(s, ProjectsFile, projectID))
+	})
+}
+
+func (s *Storage) DeleteProject(id string) error {
+	return s.withLock(func() error {
+		projects, err := loadCollection(s, ProjectsFile, projectID)
+		if err != nil {
+			return err
+		}
+		for _, p := range projects {
+			if p.ID == id {
+				return appendEntry(s, ProjectsFile, journalEntry[model.Project]{Op: opDel, ID: id}, compact(s, ProjectsFile, projectID))
+			}
+		}
+		return ErrNotFound
+	})
+}
+
+func projectID(p model.Project) string { return p.ID }
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+)
+
+// SchemaVersion is the format version this build writes into notes.json and
+// todos.json:
+//
+//	{"version": 1, "items": [...]}
+//
+// Version 0 is the original format, a bare JSON array.
+const SchemaVersion = 1
+
+// ErrNewerVersion is returned when a data file was written by a newer noteme
+// than this one. Such files are never modified.
+var ErrNewerVersion = errors.New("storage: data file was written by a newer version of noteme")
+
+type envelope struct {
+	Version int             `json:"version"`
+	Items   json.RawMessage `json:"items"`
+}
+
+// A migration turns the items of file at version N into version N+1. It
+// receives the raw items array so it can rename or reshape fields before
+// they are decoded into the current model types.
+type migration func(file string, items json.RawMessage) (json.RawMessage, error)
+
+// migrations is keyed by the version a migration upgrades from. Every
+// version below SchemaVersion needs an entry.
+var migrations = map[int]migration{
+	// v0 -> v1: the items are unchanged, only the envelope is new.
+	0: func(file string, items json.RawMessage) (json.RawMessage, error) { return items, nil },
+}
+
+// encodeVersioned wraps items in the current envelope.
+func encodeVersioned(items any) ([]byte, error) {
+	raw, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(envelope{Version: SchemaVersion, Items: raw}, "", "  ")
+}
+
+// decodeVersioned decodes a data file of any supported version into v,
+// running the migrations it needs, and returns the version it was stored as.
+func decodeVersioned(file string, data []byte, v any) (int, error) {
+	var env envelope
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		env = envelope{Version: 0, Items: trimmed}
+	} else if err := json.Unmarshal(data, &env); err != nil {
+		return 0, err
+	} else if env.Items == nil {
+		return 0, fmt.Errorf("%s: missing items", file)
+	}
+
+	if env.Version > SchemaVersion {
+		return env.Version, fmt.Errorf("%s is version %d, this build understands up to %d: %w",
+			file, env.Version, SchemaVersion, ErrNewerVersion)
+	}
+
+	items := env.Items
+	for version := env.Version; version < SchemaVersion; version++ {
+		migrate, ok := migrations[version]
+		if !ok {
+			return env.Version, fmt.Errorf("%s: no migration from version %d", file, version)
+		}
+		var err error
+		if items, err = migrate(file, items); err != nil {
+			return env.Version, fmt.Errorf("%s: migrate from version %d: %w", file, version, err)
+		}
+	}
+	return env.Version, json.Unmarshal(items, v)
+}
+
+// checkVersion fails with ErrNewerVersion if the file at path was written by
+// a newer noteme, so that it is not overwritten or appended to. It only reads
+// the start of the file, where encodeVersioned puts the version, to keep
+// single-item writes cheap. Anything it cannot make sense of is left for the
+// normal load path to deal with.
+func checkVersion(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	head := make([]byte, 64)
+	n, _ := io.ReadFull(f, head)
+	m := versionPrefix.FindSubmatch(head[:n])
+	if m == nil {
+		return nil
+	}
+	version, err := strconv.Atoi(string(m[1]))
+	if err != nil || version <= SchemaVersion {
+		return nil
+	}
+	return fmt.Errorf("%s is version %d, this build understands up to %d: %w",
+		filepath.Base(path), version, SchemaVersion, ErrNewerVersion)
+}
+
+var versionPrefix = regexp.MustCompile(`^\s*\{\s*"version"\s*:\s*(\d+)`)
+
+// preMigrationBackup is where the original bytes of a file are kept before
+// it is rewritten in a newer format, e.g. notes.json.v0.bak.
+func preMigrationBackup(path string, version int) string {
+	return fmt.Sprintf("%s.v%d%s", path, version, backupSuffix)
+}
+
+// upgradeFile rewrites path, whose original contents were data at version
+// from, in the current format. The original is kept next to it first.
+func upgradeFile(path string, data []byte, from int, v any) error {
+	if err := replaceFile(preMigrationBackup(path, from), data, 0644); err != nil {
+		return fmt.Errorf("%s: keep pre-migration copy: %w", filepath.Base(path), err)
+	}
+	upgraded, err := encodeVersioned(v)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, upgraded, 0644)
+}
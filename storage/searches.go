@@ -0,0 +1,45 @@
+package storage
+
+import "github.com/mtix28/noteme/model"
+
+// SearchesFile holds the saved searches.
+const SearchesFile = "searches.json"
+
+// SavedSearches is implemented by backends that store saved searches.
+type SavedSearches interface {
+	LoadSavedSearches() ([]model.SavedSearch, error)
+	UpsertSavedSearch(s model.SavedSearch) error
+	DeleteSavedSearch(id string) error
+}
+
+func (s *Storage) LoadSavedSearches() ([]model.SavedSearch, error) {
+	var searches []model.SavedSearch
+	err := s.withLock(func() (err error) {
+		searches, err = loadCollection(s, SearchesFile, savedSearchID)
+		return err
+	})
+	return searches, err
+}
+
+func (s *Storage) UpsertSavedSearch(saved model.SavedSearch) error {
+	return s.withLock(func() error {
+		return appendEntry(s, SearchesFile, journalEntry[model.SavedSearch]{Op: opPut, ID: saved.ID, Item: &saved}, compact(s, SearchesFile, savedSearchID))
+	})
+}
+
+func (s *Storage) DeleteSavedSearch(id string) error {
+	return s.withLock(func() error {
+		searches, err := loadCollection(s, SearchesFile, savedSearchID)
+		if err != nil {
+			return err
+		}
+		for _, saved := range searches {
+			if saved.ID == id {
+				return appendEntry(s, SearchesFile, journalEntry[model.SavedSearch]{Op: opDel, ID: id}, compact(s, SearchesFile, savedSearchID))
+			}
+		}
+		return ErrNotFound
+	})
+}
+
+func savedSearchID(s model.SavedSearch) string { return s.ID }
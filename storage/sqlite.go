@@ -0,0 +1,564 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"time"
+
+	"github.com/mtix28/noteme/model"
+	_ "modernc.org/sqlite" // pure-Go driver, registers "sqlite"
+)
+
+// DatabaseFile is the SQLite database SQLiteStorage keeps inside the data
+// directory.
+const DatabaseFile = "noteme.db"
+
+// SQLiteStorage keeps notes and todos in a single SQLite database. Each row
+// holds the full item as JSON next to a few plain columns, so new model
+// fields do not need a schema change.
+//
+// The first time a database is created in a data directory that already has
+// notes.json/todos.json, their contents are imported once.
+type SQLiteStorage struct {
+	db *sql.DB
+}
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS notes (
+	id       TEXT PRIMARY KEY,
+	position INTEGER NOT NULL,
+	title    TEXT NOT NULL,
+	content  TEXT NOT NULL,
+	data     TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS revisions (
+	id       TEXT PRIMARY KEY,
+	note_id  TEXT NOT NULL,
+	saved_at TEXT NOT NULL,
+	data     TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS revisions_note ON revisions (note_id, saved_at);
+
+CREATE TABLE IF NOT EXISTS trash (
+	id         TEXT PRIMARY KEY,
+	deleted_at TEXT NOT NULL,
+	data       TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS projects (
+	id   TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	data TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS saved_searches (
+	id   TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	data TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS todos (
+	id       TEXT PRIMARY KEY,
+	position INTEGER NOT NULL,
+	content  TEXT NOT NULL,
+	data     TEXT NOT NULL
+);
+
+-- Full-text indexes of earlier versions; search has its own index.
+DROP TRIGGER IF EXISTS notes_ai;
+DROP TRIGGER IF EXISTS notes_ad;
+DROP TRIGGER IF EXISTS notes_au;
+DROP TABLE IF EXISTS notes_fts;
+DROP TRIGGER IF EXISTS todos_ai;
+DROP TRIGGER IF EXISTS todos_ad;
+DROP TRIGGER IF EXISTS todos_au;
+DROP TABLE IF EXISTS todos_fts;
+`
+
+// sqliteTime is a fixed-width timestamp layout, so that text columns sort in
+// time order.
+const sqliteTime = "2006-01-02T15:04:05.000000000Z"
+
+// metaInitialized marks a database whose initial import or seeding is done.
+const metaInitialized = "initialized"
+
+// NewSQLiteStorageAt opens (creating if needed) the database in the data
+// directory dir.
+func NewSQLiteStorageAt(dir string) (*SQLiteStorage, error) {
+	base, err := NewStorageAt(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	dsn := "file:" + (&url.URL{Path: filepath.Join(dir, DatabaseFile)}).EscapedPath() +
+		"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create schema: %w", err)
+	}
+
+	s := &SQLiteStorage{db: db}
+	if err := s.initialize(base); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close releases the database.
+func (s *SQLiteStorage) Close() error {
+	return s.db.Close()
+}
+
+// initialize runs once per database: it imports notes.json/todos.json from
+// the JSON store in the same directory if there are any, and otherwise seeds
+// the welcome note and todo.
+func (s *SQLiteStorage) initialize(legacy *Storage) error {
+	var done string
+	err := s.db.QueryRow(`SELECT value FROM meta WHERE key = ?`, metaInitialized).Scan(&done)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	notes := []model.Note{welcomeNote()}
+	todos := []model.Todo{welcomeTodo()}
+	if hasJSONData(legacy.basePath, NotesFile) {
+		if notes, err = legacy.LoadNotes(); err != nil {
+			return fmt.Errorf("migrate %s: %w", NotesFile, err)
+		}
+	}
+	if hasJSONData(legacy.basePath, TodosFile) {
+		if todos, err = legacy.LoadTodos(); err != nil {
+			return fmt.Errorf("migrate %s: %w", TodosFile, err)
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := replaceNotes(tx, notes); err != nil {
+		return err
+	}
+	if err := replaceTodos(tx, todos); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO meta (key, value) VALUES (?, 'yes')`, metaInitialized); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStorage) LoadNotes() ([]model.Note, error) {
+	return queryItems[model.Note](s.db, `SELECT data FROM notes ORDER BY position`)
+}
+
+func (s *SQLiteStorage) SaveNotes(notes []model.Note) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := replaceNotes(tx, notes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpsertNote replaces the note with the same ID, or inserts it at the top.
+func (s *SQLiteStorage) UpsertNote(note model.Note) error {
+	return upsertNote(s.db, note)
+}
+
+// execer is what *sql.DB and *sql.Tx have in common.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+func upsertNote(db execer, note model.Note) error {
+	data, err := json.Marshal(note)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+		INSERT INTO notes (id, position, title, content, data)
+		VALUES (?, (SELECT IFNULL(MIN(position), 0) - 1 FROM notes), ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET title = excluded.title, content = excluded.content, data = excluded.data`,
+		note.ID, note.Title, note.Content, string(data))
+	return err
+}
+
+// UpsertNoteFrom is UpsertNote merged with any change made to the stored
+// note since base was loaded; see Versioned.
+func (s *SQLiteStorage) UpsertNoteFrom(base, note model.Note) (bool, error) {
+	return upsertFrom(s.db, `notes`, base, note, note.ID, upsertNote)
+}
+
+// upsertFrom reads the stored item and writes the rebased one in a single
+// transaction.
+func upsertFrom[T any](db *sql.DB, table string, base, item T, id string, upsert func(execer, T) error) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var stored T
+	var data string
+	err = tx.QueryRow(`SELECT data FROM `+table+` WHERE id = ?`, id).Scan(&data)
+	found := err == nil
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return false, err
+	default:
+		if err := json.Unmarshal([]byte(data), &stored); err != nil {
+			return false, err
+		}
+	}
+	item, merged, err := rebase(base, item, stored, found)
+	if err != nil {
+		return false, err
+	}
+	if err := upsert(tx, item); err != nil {
+		return false, err
+	}
+	return merged, tx.Commit()
+}
+
+func (s *SQLiteStorage) GetNote(id string) (model.Note, error) {
+	return queryItem[model.Note](s.db, `SELECT data FROM notes WHERE id = ?`, id)
+}
+
+// DeleteNote moves the note into the trash.
+func (s *SQLiteStorage) DeleteNote(id string) error {
+	return s.trashRow(`notes`, id, func(data string) (model.TrashItem, error) {
+		var n model.Note
+		err := json.Unmarshal([]byte(data), &n)
+		return model.TrashedNote(n, time.Now()), err
+	})
+}
+
+func (s *SQLiteStorage) AddRevision(rev model.Revision) error {
+	data, err := json.Marshal(rev)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT OR REPLACE INTO revisions (id, note_id, saved_at, data) VALUES (?, ?, ?, ?)`,
+		rev.ID, rev.NoteID, rev.SavedAt.UTC().Format(sqliteTime), string(data))
+	return err
+}
+
+func (s *SQLiteStorage) Revisions(noteID string) ([]model.Revision, error) {
+	return queryItems[model.Revision](s.db, `SELECT data FROM revisions WHERE note_id = ? ORDER BY saved_at DESC`, noteID)
+}
+
+func (s *SQLiteStorage) DeleteRevisions(noteID string) error {
+	_, err := s.db.Exec(`DELETE FROM revisions WHERE note_id = ?`, noteID)
+	return err
+}
+
+func (s *SQLiteStorage) LoadProjects() ([]model.Project, error) {
+	return queryItems[model.Project](s.db, `SELECT data FROM projects ORDER BY name`)
+}
+
+func (s *SQLiteStorage) UpsertProject(p model.Project) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT OR REPLACE INTO projects (id, name, data) VALUES (?, ?, ?)`, p.ID, p.Name, string(data))
+	return err
+}
+
+func (s *SQLiteStorage) DeleteProject(id string) error {
+	res, err := s.db.Exec(`DELETE FROM projects WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *SQLiteStorage) LoadSavedSearches() ([]model.SavedSearch, error) {
+	return queryItems[model.SavedSearch](s.db, `SELECT data FROM saved_searches ORDER BY name`)
+}
+
+func (s *SQLiteStorage) UpsertSavedSearch(saved model.SavedSearch) error {
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT OR REPLACE INTO saved_searches (id, name, data) VALUES (?, ?, ?)`, saved.ID, saved.Name, string(data))
+	return err
+}
+
+func (s *SQLiteStorage) DeleteSavedSearch(id string) error {
+	res, err := s.db.Exec(`DELETE FROM saved_searches WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *SQLiteStorage) LoadTodos() ([]model.Todo, error) {
+	return queryItems[model.Todo](s.db, `SELECT data FROM todos ORDER BY position`)
+}
+
+func (s *SQLiteStorage) SaveTodos(todos []model.Todo) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := replaceTodos(tx, todos); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpsertTodo replaces the todo with the same ID, or inserts it at the top.
+func (s *SQLiteStorage) UpsertTodo(todo model.Todo) error {
+	return upsertTodo(s.db, todo)
+}
+
+func upsertTodo(db execer, todo model.Todo) error {
+	data, err := json.Marshal(todo)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+		INSERT INTO todos (id, position, content, data)
+		VALUES (?, (SELECT IFNULL(MIN(position), 0) - 1 FROM todos), ?, ?)
+		ON CONFLICT(id) DO UPDATE SET content = excluded.content, data = excluded.data`,
+		todo.ID, todo.Content, string(data))
+	return err
+}
+
+// UpsertTodoFrom is UpsertNoteFrom for todos.
+func (s *SQLiteStorage) UpsertTodoFrom(base, todo model.Todo) (bool, error) {
+	return upsertFrom(s.db, `todos`, base, todo, todo.ID, upsertTodo)
+}
+
+func (s *SQLiteStorage) GetTodo(id string) (model.Todo, error) {
+	return queryItem[model.Todo](s.db, `SELECT data FROM todos WHERE id = ?`, id)
+}
+
+// DeleteTodo moves the todo into the trash.
+func (s *SQLiteStorage) DeleteTodo(id string) error {
+	return s.trashRow(`todos`, id, func(data string) (model.TrashItem, error) {
+		var t model.Todo
+		err := json.Unmarshal([]byte(data), &t)
+		return model.TrashedTodo(t, time.Now()), err
+	})
+}
+
+// trashRow moves row id of table into the trash in one transaction.
+func (s *SQLiteStorage) trashRow(table, id string, toItem func(data string) (model.TrashItem, error)) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var data string
+	err = tx.QueryRow(`SELECT data FROM `+table+` WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	item, err := toItem(data)
+	if err != nil {
+		return err
+	}
+	if err := insertTrash(tx, item); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM `+table+` WHERE id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertTrash(db execer, item model.TrashItem) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT OR REPLACE INTO trash (id, deleted_at, data) VALUES (?, ?, ?)`,
+		item.ID, item.DeletedAt.UTC().Format(sqliteTime), string(data))
+	return err
+}
+
+func (s *SQLiteStorage) TrashedItems() ([]model.TrashItem, error) {
+	return queryItems[model.TrashItem](s.db, `SELECT data FROM trash ORDER BY deleted_at DESC`)
+}
+
+func (s *SQLiteStorage) RestoreItem(id string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var data string
+	err = tx.QueryRow(`SELECT data FROM trash WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	var item model.TrashItem
+	if err := json.Unmarshal([]byte(data), &item); err != nil {
+		return err
+	}
+	if item.Note != nil {
+		err = upsertNote(tx, *item.Note)
+	} else {
+		err = upsertTodo(tx, *item.Todo)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM trash WHERE id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStorage) PurgeItem(id string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	// A trashed note keeps its ID, which its revisions refer to.
+	if _, err := tx.Exec(`DELETE FROM revisions WHERE note_id IN (SELECT id FROM trash WHERE id = ?)`, id); err != nil {
+		return err
+	}
+	res, err := tx.Exec(`DELETE FROM trash WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStorage) PurgeTrash(cutoff time.Time) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	before := cutoff.UTC().Format(sqliteTime)
+	if _, err := tx.Exec(`DELETE FROM revisions WHERE note_id IN (SELECT id FROM trash WHERE deleted_at < ?)`, before); err != nil {
+		return 0, err
+	}
+	res, err := tx.Exec(`DELETE FROM trash WHERE deleted_at < ?`, before)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(n), tx.Commit()
+}
+
+func replaceNotes(tx *sql.Tx, notes []model.Note) error {
+	if _, err := tx.Exec(`DELETE FROM notes`); err != nil {
+		return err
+	}
+	for i, n := range notes {
+		data, err := json.Marshal(n)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT INTO notes (id, position, title, content, data) VALUES (?, ?, ?, ?, ?)`,
+			n.ID, i, n.Title, n.Content, string(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func replaceTodos(tx *sql.Tx, todos []model.Todo) error {
+	if _, err := tx.Exec(`DELETE FROM todos`); err != nil {
+		return err
+	}
+	for i, t := range todos {
+		data, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT INTO todos (id, position, content, data) VALUES (?, ?, ?, ?)`,
+			t.ID, i, t.Content, string(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func queryItems[T any](db *sql.DB, query string, args ...any) ([]T, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []T
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var item T
+		if err := json.Unmarshal([]byte(data), &item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func queryItem[T any](db *sql.DB, query string, args ...any) (T, error) {
+	var item T
+	var data string
+	err := db.QueryRow(query, args...).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return item, ErrNotFound
+	}
+	if err != nil {
+		return item, err
+	}
+	err = json.Unmarshal([]byte(data), &item)
+	return item, err
+}
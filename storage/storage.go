@@ -1,10 +1,10 @@
 package storage
 
 import (
-	"encoding/json"
 	"os"
-	"path/filepath"
+	"sync"
 	"time"
+
 	"github.com/mtix28/noteme/model"
 )
 
@@ -16,137 +16,274 @@ const (
 
 type Storage struct {
 	basePath string
+
+	mu    sync.Mutex
+	etags map[string]string // file name -> revision last read or written
 }
 
+// NewStorage opens the store in the default data directory, as resolved by
+// DataDir with no flag value.
 func NewStorage() (*Storage, error) {
-	home, err := os.UserHomeDir()
+	basePath, err := DataDir("")
 	if err != nil {
 		return nil, err
 	}
-	basePath := filepath.Join(home, DirName)
-	if err := os.MkdirAll(basePath, 0755); err != nil {
+	return NewStorageAt(basePath)
+}
+
+// NewStorageAt opens the store rooted at path, creating the directory if it
+// does not exist yet.
+func NewStorageAt(path string) (*Storage, error) {
+	if err := os.MkdirAll(path, 0755); err != nil {
 		return nil, err
 	}
-	return &Storage{basePath: basePath}, nil
+	return &Storage{basePath: path, etags: map[string]string{}}, nil
+}
+
+// Path returns the directory the store reads from and writes to.
+func (s *Storage) Path() string {
+	return s.basePath
+}
+
+// welcomeNote is the note a fresh data directory starts with.
+func welcomeNote() model.Note {
+	return model.Note{
+		ID:        "welcome-note",
+		Title:     "Welcome to NoteMe!",
+		Content:   "This is your first note.\n\n- Press 'Enter' to edit this note.\n- Press 'n' to create a new one.\n- Press 'd' to delete.\n\nEnjoy using NoteMe!",
+		Folder:    "General",
+		CreatedAt: time.Now(),
+	}
 }
 
 func (s *Storage) LoadNotes() ([]model.Note, error) {
-	path := filepath.Join(s.basePath, NotesFile)
-    if _, err := os.Stat(path); os.IsNotExist(err) {
+	var notes []model.Note
+	err := s.withLock(func() (err error) {
+		if notes, err = s.loadNotes(); err != nil {
+			return err
+		}
+		return s.rememberETag(NotesFile)
+	})
+	return notes, err
+}
+
+// SaveNotes replaces the whole collection. It fails with ErrConflict if the
+// file changed on disk since this Storage last loaded or saved it.
+func (s *Storage) SaveNotes(notes []model.Note) error {
+	return s.withLock(func() error {
+		if err := s.checkETag(NotesFile); err != nil {
+			return err
+		}
+		return s.saveNotes(notes)
+	})
+}
+
+func (s *Storage) loadNotes() ([]model.Note, error) {
+    if !hasJSONData(s.basePath, NotesFile) {
         // Seed default note
-        defaultNotes := []model.Note{
-            {
-                ID:        "welcome-note",
-                Title:     "Welcome to NoteMe!",
-                Content:   "This is your first note.\n\n- Press 'Enter' to edit this note.\n- Press 'n' to create a new one.\n- Press 'd' to delete.\n\nEnjoy using NoteMe!",
-                Folder:    "General",
-                CreatedAt: time.Now(),
-            },
-        }
-        if err := s.SaveNotes(defaultNotes); err != nil {
+        defaultNotes := []model.Note{welcomeNote()}
+        if err := s.saveNotes(defaultNotes); err != nil {
             return nil, err
         }
         return defaultNotes, nil
     }
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-	var notes []model.Note
-	if err := json.Unmarshal(data, &notes); err != nil {
-		return nil, err
-	}
-	return notes, nil
+	return loadCollection(s, NotesFile, func(n model.Note) string { return n.ID })
 }
 
-func (s *Storage) SaveNotes(notes []model.Note) error {
-	path := filepath.Join(s.basePath, NotesFile)
-	data, err := json.MarshalIndent(notes, "", "  ")
+func (s *Storage) saveNotes(notes []model.Note) error {
+	return saveCollection(s, NotesFile, notes)
+}
+
+// UpsertNote inserts or replaces a single note by appending it to the
+// journal, without rewriting the rest of the collection.
+func (s *Storage) UpsertNote(n model.Note) error {
+	return s.withLock(func() error {
+		return appendEntry(s, NotesFile, journalEntry[model.Note]{Op: opPut, ID: n.ID, Item: &n}, s.compactNotes)
+	})
+}
+
+// UpsertNoteFrom journals note like UpsertNote, merged with any change made
+// to the stored note since base was loaded; see Versioned.
+func (s *Storage) UpsertNoteFrom(base, note model.Note) (merged bool, err error) {
+	err = s.withLock(func() error {
+		notes, err := s.loadNotes()
+		if err != nil {
+			return err
+		}
+		stored, found := findByID(notes, note.ID, func(n model.Note) string { return n.ID })
+		if note, merged, err = rebase(base, note, stored, found); err != nil {
+			return err
+		}
+		return appendEntry(s, NotesFile, journalEntry[model.Note]{Op: opPut, ID: note.ID, Item: &note}, s.compactNotes)
+	})
+	return merged, err
+}
+
+func (s *Storage) compactNotes() error {
+	notes, err := s.loadNotes()
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0644)
+	return s.saveNotes(notes)
+}
+
+func (s *Storage) GetNote(id string) (model.Note, error) {
+	var found model.Note
+	err := s.withLock(func() error {
+		notes, err := s.loadNotes()
+		if err != nil {
+			return err
+		}
+		for _, n := range notes {
+			if n.ID == id {
+				found = n
+				return nil
+			}
+		}
+		return ErrNotFound
+	})
+	return found, err
 }
 
 func (s *Storage) DeleteNote(id string) error {
-    notes, err := s.LoadNotes()
-    if err != nil {
-        return err
-    }
-    newNotes := []model.Note{}
-    found := false
-    for _, n := range notes {
-        if n.ID != id {
-            newNotes = append(newNotes, n)
-        } else {
-            found = true
-        }
-    }
-    
-    // Optimization: if not found, don't write
-    if !found {
-        return nil
-    }
+	return s.withLock(func() error { return s.deleteNote(id) })
+}
+
+// deleteNote moves the note into the trash. Callers hold the lock.
+func (s *Storage) deleteNote(id string) error {
+	notes, err := s.loadNotes()
+	if err != nil {
+		return err
+	}
+	for _, n := range notes {
+		if n.ID != id {
+			continue
+		}
+		if err := s.moveToTrash(model.TrashedNote(n, time.Now())); err != nil {
+			return err
+		}
+		return appendEntry(s, NotesFile, journalEntry[model.Note]{Op: opDel, ID: id}, s.compactNotes)
+	}
+	return nil
+}
 
-    return s.SaveNotes(newNotes)
+// welcomeTodo is the todo a fresh data directory starts with.
+func welcomeTodo() model.Todo {
+	return model.Todo{
+		ID:        "welcome-todo",
+		Content:   "Try creating a new todo (Press 't')",
+		Done:      false,
+		CreatedAt: time.Now(),
+		Frequency: model.Once,
+	}
 }
 
 func (s *Storage) LoadTodos() ([]model.Todo, error) {
-	path := filepath.Join(s.basePath, TodosFile)
-    if _, err := os.Stat(path); os.IsNotExist(err) {
+	var todos []model.Todo
+	err := s.withLock(func() (err error) {
+		if todos, err = s.loadTodos(); err != nil {
+			return err
+		}
+		return s.rememberETag(TodosFile)
+	})
+	return todos, err
+}
+
+// SaveTodos replaces the whole collection. It fails with ErrConflict if the
+// file changed on disk since this Storage last loaded or saved it.
+func (s *Storage) SaveTodos(todos []model.Todo) error {
+	return s.withLock(func() error {
+		if err := s.checkETag(TodosFile); err != nil {
+			return err
+		}
+		return s.saveTodos(todos)
+	})
+}
+
+func (s *Storage) loadTodos() ([]model.Todo, error) {
+    if !hasJSONData(s.basePath, TodosFile) {
         // Seed default todo
-        defaultTodos := []model.Todo{
-            {
-                ID:        "welcome-todo",
-                Content:   "Try creating a new todo (Press 't')",
-                Done:      false,
-                CreatedAt: time.Now(),
-                Frequency: model.Once,
-            },
-        }
-        if err := s.SaveTodos(defaultTodos); err != nil {
+        defaultTodos := []model.Todo{welcomeTodo()}
+        if err := s.saveTodos(defaultTodos); err != nil {
             return nil, err
         }
         return defaultTodos, nil
     }
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-	var todos []model.Todo
-	if err := json.Unmarshal(data, &todos); err != nil {
-		return nil, err
-	}
-	return todos, nil
+	return loadCollection(s, TodosFile, func(t model.Todo) string { return t.ID })
 }
 
-func (s *Storage) SaveTodos(todos []model.Todo) error {
-	path := filepath.Join(s.basePath, TodosFile)
-	data, err := json.MarshalIndent(todos, "", "  ")
+func (s *Storage) saveTodos(todos []model.Todo) error {
+	return saveCollection(s, TodosFile, todos)
+}
+
+// UpsertTodo inserts or replaces a single todo by appending it to the
+// journal, without rewriting the rest of the collection.
+func (s *Storage) UpsertTodo(t model.Todo) error {
+	return s.withLock(func() error {
+		return appendEntry(s, TodosFile, journalEntry[model.Todo]{Op: opPut, ID: t.ID, Item: &t}, s.compactTodos)
+	})
+}
+
+// UpsertTodoFrom is UpsertNoteFrom for todos.
+func (s *Storage) UpsertTodoFrom(base, todo model.Todo) (merged bool, err error) {
+	err = s.withLock(func() error {
+		todos, err := s.loadTodos()
+		if err != nil {
+			return err
+		}
+		stored, found := findByID(todos, todo.ID, func(t model.Todo) string { return t.ID })
+		if todo, merged, err = rebase(base, todo, stored, found); err != nil {
+			return err
+		}
+		return appendEntry(s, TodosFile, journalEntry[model.Todo]{Op: opPut, ID: todo.ID, Item: &todo}, s.compactTodos)
+	})
+	return merged, err
+}
+
+func (s *Storage) compactTodos() error {
+	todos, err := s.loadTodos()
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0644)
+	return s.saveTodos(todos)
 }
 
-func (s *Storage) DeleteTodo(id string) error {
-    todos, err := s.LoadTodos()
-    if err != nil {
-        return err
-    }
-    newTodos := []model.Todo{}
-    found := false
-    for _, t := range todos {
-        if t.ID != id {
-            newTodos = append(newTodos, t)
-        } else {
-            found = true
-        }
-    }
+func (s *Storage) GetTodo(id string) (model.Todo, error) {
+	var found model.Todo
+	err := s.withLock(func() error {
+		todos, err := s.loadTodos()
+		if err != nil {
+			return err
+		}
+		for _, t := range todos {
+			if t.ID == id {
+				found = t
+				return nil
+			}
+		}
+		return ErrNotFound
+	})
+	return found, err
+}
 
-    if !found {
-        return nil
-    }
+func (s *Storage) DeleteTodo(id string) error {
+	return s.withLock(func() error { return s.deleteTodo(id) })
+}
 
-    return s.SaveTodos(newTodos)
-}
\ No newline at end of file
+// deleteTodo moves the todo into the trash. Callers hold the lock.
+func (s *Storage) deleteTodo(id string) error {
+	todos, err := s.loadTodos()
+	if err != nil {
+		return err
+	}
+	for _, t := range todos {
+		if t.ID != id {
+			continue
+		}
+		if err := s.moveToTrash(model.TrashedTodo(t, time.Now())); err != nil {
+			return err
+		}
+		return appendEntry(s, TodosFile, journalEntry[model.Todo]{Op: opDel, ID: id}, s.compactTodos)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package storage_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mtix28/noteme/model"
+	"github.com/mtix28/noteme/storage"
+)
+
+func TestSaveNotesKeepsBackup(t *testing.T) {
+	store, dir := setupTestStorage(t)
+	defer cleanupTestStorage(dir)
+
+	store.SaveNotes([]model.Note{{ID: "1", Title: "First"}})
+	store.SaveNotes([]model.Note{{ID: "1", Title: "Second"}})
+
+	backup, err := os.ReadFile(filepath.Join(dir, storage.NotesFile+".bak"))
+	if err != nil {
+		t.Fatalf("Expected backup file: %v", err)
+	}
+	if !strings.Contains(string(backup), "First") {
+		t.Fatalf("Expected backup to hold the previous generation, got %s", backup)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".tmp-") {
+			t.Fatalf("Temp file left behind: %s", e.Name())
+		}
+	}
+}
+
+func TestLoadNotesRecoversFromBackup(t *testing.T) {
+	store, dir := setupTestStorage(t)
+	defer cleanupTestStorage(dir)
+
+	store.SaveNotes([]model.Note{{ID: "1", Title: "Good"}})
+	store.SaveNotes([]model.Note{{ID: "1", Title: "Good"}, {ID: "2", Title: "Newer"}})
+
+	// Simulate a write that died halfway through.
+	path := filepath.Join(dir, storage.NotesFile)
+	if err := os.WriteFile(path, []byte(`[{"id": "1", "tit`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	notes, err := store.LoadNotes()
+	if err != nil {
+		t.Fatalf("Expected recovery from backup, got %v", err)
+	}
+	if len(notes) != 1 || notes[0].Title != "Good" {
+		t.Fatalf("Expected backup contents, got %+v", notes)
+	}
+
+	if _, err := os.Stat(path + ".corrupt"); err != nil {
+		t.Fatalf("Expected damaged file to be set aside: %v", err)
+	}
+
+	// The live file is healthy again.
+	notes, err = store.LoadNotes()
+	if err != nil || len(notes) != 1 {
+		t.Fatalf("Expected restored file to load, got %v, %+v", err, notes)
+	}
+}
+
+func TestLoadTodosFailsWithoutBackup(t *testing.T) {
+	store, dir := setupTestStorage(t)
+	defer cleanupTestStorage(dir)
+
+	path := filepath.Join(dir, storage.TodosFile)
+	if err := os.WriteFile(path, []byte(`not json`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.LoadTodos(); err == nil {
+		t.Fatal("Expected an error for a damaged file with no backup")
+	}
+}
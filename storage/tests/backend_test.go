@@ -0,0 +1,450 @@
+package storage_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mtix28/noteme/model"
+	"github.com/mtix28/noteme/storage"
+)
+
+// backends returns one fresh instance of every Backend implementation so the
+// same behaviour can be checked against each of them.
+func backends(t *testing.T) map[string]storage.Backend {
+	store, dir := setupTestStorage(t)
+	t.Cleanup(func() { cleanupTestStorage(dir) })
+
+	md, err := storage.NewMarkdownStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create markdown storage: %v", err)
+	}
+
+	db, err := storage.NewSQLiteStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create sqlite storage: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return map[string]storage.Backend{
+		"json":     store,
+		"memory":   storage.NewMemoryStorage(),
+		"markdown": md,
+		"sqlite":   db,
+	}
+}
+
+func TestBackendGetNote(t *testing.T) {
+	for name, b := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			note := model.Note{ID: "n1", Title: "Hello", Content: "World", Folder: "general", CreatedAt: time.Now()}
+			if err := b.SaveNotes([]model.Note{note}); err != nil {
+				t.Fatalf("SaveNotes: %v", err)
+			}
+
+			got, err := b.GetNote("n1")
+			if err != nil {
+				t.Fatalf("GetNote: %v", err)
+			}
+			if got.Title != "Hello" || got.Content != "World" {
+				t.Fatalf("unexpected note: %+v", got)
+			}
+
+			if _, err := b.GetNote("missing"); !errors.Is(err, storage.ErrNotFound) {
+				t.Fatalf("expected ErrNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBackendGetTodo(t *testing.T) {
+	for name, b := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			todo := model.Todo{ID: "t1", Content: "Buy milk", CreatedAt: time.Now(), Frequency: model.Once}
+			if err := b.SaveTodos([]model.Todo{todo}); err != nil {
+				t.Fatalf("SaveTodos: %v", err)
+			}
+
+			got, err := b.GetTodo("t1")
+			if err != nil {
+				t.Fatalf("GetTodo: %v", err)
+			}
+			if got.Content != "Buy milk" {
+				t.Fatalf("unexpected todo: %+v", got)
+			}
+
+			if err := b.DeleteTodo("t1"); err != nil {
+				t.Fatalf("DeleteTodo: %v", err)
+			}
+			if _, err := b.GetTodo("t1"); !errors.Is(err, storage.ErrNotFound) {
+				t.Fatalf("expected ErrNotFound after delete, got %v", err)
+			}
+		})
+	}
+}
+
+func TestMemoryStorageReturnsCopies(t *testing.T) {
+	b := storage.NewMemoryStorage()
+	b.SaveNotes([]model.Note{{ID: "n1", Title: "Original"}})
+
+	notes, _ := b.LoadNotes()
+	notes[0].Title = "Mutated"
+
+	got, _ := b.GetNote("n1")
+	if got.Title != "Original" {
+		t.Fatalf("expected stored note to be unaffected, got %q", got.Title)
+	}
+}
+
+func TestBackendUpsertNote(t *testing.T) {
+	for name, b := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			b.SaveNotes([]model.Note{{ID: "n1", Title: "Old", CreatedAt: time.Now()}})
+
+			if err := b.UpsertNote(model.Note{ID: "n1", Title: "New", CreatedAt: time.Now(), Tags: []string{"work"}}); err != nil {
+				t.Fatalf("UpsertNote existing: %v", err)
+			}
+			if err := b.UpsertNote(model.Note{ID: "n2", Title: "Added", CreatedAt: time.Now()}); err != nil {
+				t.Fatalf("UpsertNote new: %v", err)
+			}
+
+			notes, err := b.LoadNotes()
+			if err != nil {
+				t.Fatalf("LoadNotes: %v", err)
+			}
+			if len(notes) != 2 {
+				t.Fatalf("Expected 2 notes, got %+v", notes)
+			}
+			if got, _ := b.GetNote("n1"); got.Title != "New" || len(got.Tags) != 1 || got.Tags[0] != "work" {
+				t.Fatalf("Expected n1 to be replaced, got %+v", got)
+			}
+		})
+	}
+}
+
+func TestBackendRevisions(t *testing.T) {
+	for name, b := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			history, ok := b.(storage.History)
+			if !ok {
+				t.Skip("backend keeps no history")
+			}
+
+			base := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+			note := model.Note{ID: "n1", Title: "Draft", Content: "v1"}
+			history.AddRevision(model.NewRevision("r1", note, base))
+			note.Content = "v2"
+			history.AddRevision(model.NewRevision("r2", note, base.Add(time.Hour)))
+			history.AddRevision(model.NewRevision("other", model.Note{ID: "n2"}, base))
+
+			revs, err := history.Revisions("n1")
+			if err != nil {
+				t.Fatalf("Revisions: %v", err)
+			}
+			if len(revs) != 2 || revs[0].Content != "v2" || revs[1].Content != "v1" {
+				t.Fatalf("Expected both versions of n1, newest first, got %+v", revs)
+			}
+			if restored := revs[1].Apply(note); restored.Content != "v1" || restored.ID != "n1" {
+				t.Fatalf("Unexpected restored note: %+v", restored)
+			}
+		})
+	}
+}
+
+func TestBackendTrash(t *testing.T) {
+	for name, b := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			trash, ok := b.(storage.Trash)
+			if !ok {
+				t.Skip("backend has no trash")
+			}
+
+			b.SaveNotes([]model.Note{{ID: "n1", Title: "Keep me", Folder: "work", CreatedAt: time.Now()}})
+			b.SaveTodos([]model.Todo{{ID: "t1", Content: "Old chore"}})
+
+			if err := b.DeleteNote("n1"); err != nil {
+				t.Fatalf("DeleteNote: %v", err)
+			}
+			if err := b.DeleteTodo("t1"); err != nil {
+				t.Fatalf("DeleteTodo: %v", err)
+			}
+			if _, err := b.GetNote("n1"); !errors.Is(err, storage.ErrNotFound) {
+				t.Fatalf("Expected n1 to be gone from the notes, got %v", err)
+			}
+
+			items, err := trash.TrashedItems()
+			if err != nil {
+				t.Fatalf("TrashedItems: %v", err)
+			}
+			if len(items) != 2 {
+				t.Fatalf("Expected 2 trashed items, got %+v", items)
+			}
+
+			if err := trash.RestoreItem("n1"); err != nil {
+				t.Fatalf("RestoreItem: %v", err)
+			}
+			got, err := b.GetNote("n1")
+			if err != nil || got.Title != "Keep me" || got.Folder != "work" {
+				t.Fatalf("Expected n1 back intact, got %+v, %v", got, err)
+			}
+
+			if err := trash.PurgeItem("t1"); err != nil {
+				t.Fatalf("PurgeItem: %v", err)
+			}
+			if items, _ := trash.TrashedItems(); len(items) != 0 {
+				t.Fatalf("Expected an empty trash, got %+v", items)
+			}
+			if err := trash.RestoreItem("t1"); !errors.Is(err, storage.ErrNotFound) {
+				t.Fatalf("Expected a purged item to be gone, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBackendPurgeTrash(t *testing.T) {
+	for name, b := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			trash, ok := b.(storage.Trash)
+			if !ok {
+				t.Skip("backend has no trash")
+			}
+
+			b.SaveTodos([]model.Todo{{ID: "t1"}, {ID: "t2"}})
+			b.DeleteTodo("t1")
+			b.DeleteTodo("t2")
+
+			if n, err := trash.PurgeTrash(time.Now().Add(-time.Hour)); err != nil || n != 0 {
+				t.Fatalf("Expected nothing older than an hour, purged %d, %v", n, err)
+			}
+			if n, err := trash.PurgeTrash(time.Now().Add(time.Minute)); err != nil || n != 2 {
+				t.Fatalf("Expected both items to expire, purged %d, %v", n, err)
+			}
+		})
+	}
+}
+
+func TestBackendPurgeDropsHistory(t *testing.T) {
+	for name, b := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			trash, ok := b.(storage.Trash)
+			history, ok2 := b.(storage.History)
+			if !ok || !ok2 {
+				t.Skip("backend has no trash or history")
+			}
+
+			now := time.Now()
+			notes := []model.Note{{ID: "n1", Title: "One"}, {ID: "n2", Title: "Two"}, {ID: "n3", Title: "Three"}}
+			b.SaveNotes(notes)
+			for _, n := range notes {
+				history.AddRevision(model.NewRevision("r"+n.ID, n, now))
+				b.DeleteNote(n.ID)
+			}
+			trash.RestoreItem("n3")
+
+			if err := trash.PurgeItem("n1"); err != nil {
+				t.Fatalf("PurgeItem: %v", err)
+			}
+			if revs, err := history.Revisions("n1"); err != nil || len(revs) != 0 {
+				t.Fatalf("Expected n1's history to go with it, got %v, %v", revs, err)
+			}
+			if revs, _ := history.Revisions("n2"); len(revs) != 1 {
+				t.Fatalf("Expected n2's history to stay while it is in the trash, got %v", revs)
+			}
+
+			if n, err := trash.PurgeTrash(now.Add(time.Minute)); err != nil || n != 1 {
+				t.Fatalf("PurgeTrash = %d, %v", n, err)
+			}
+			if revs, _ := history.Revisions("n2"); len(revs) != 0 {
+				t.Fatalf("Expected n2's history to be purged, got %v", revs)
+			}
+			if revs, _ := history.Revisions("n3"); len(revs) != 1 {
+				t.Fatalf("Expected the restored note to keep its history, got %v", revs)
+			}
+		})
+	}
+}
+
+func TestBackendKeepsTodoSchedule(t *testing.T) {
+	day := time.Date(2024, time.May, 10, 0, 0, 0, 0, time.Local)
+	todo := model.Todo{
+		ID:          "t1",
+		Content:     "Pay rent",
+		CreatedAt:   day.AddDate(0, 0, -7),
+		Frequency:   model.Monthly,
+		Recurrence:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+		Due:         day,
+		Start:       day.AddDate(0, 0, -3),
+		Priority:    model.PriorityHigh,
+		Tags:        []string{"home"},
+		Completions: []time.Time{day.AddDate(0, -1, 0)},
+	}
+	for name, b := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := b.UpsertTodo(todo); err != nil {
+				t.Fatalf("UpsertTodo: %v", err)
+			}
+			got, err := b.GetTodo("t1")
+			if err != nil {
+				t.Fatalf("GetTodo: %v", err)
+			}
+			if got.Recurrence != todo.Recurrence || !got.Due.Equal(todo.Due) || !got.Start.Equal(todo.Start) ||
+				got.Priority != todo.Priority || len(got.Tags) != 1 || got.Tags[0] != "home" ||
+				len(got.Completions) != 1 || !got.Completions[0].Equal(todo.Completions[0]) {
+				t.Fatalf("Got %+v, want %+v", got, todo)
+			}
+		})
+	}
+}
+
+func TestBackendProjects(t *testing.T) {
+	for name, b := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			projects, ok := b.(storage.Projects)
+			if !ok {
+				t.Skip("backend has no projects")
+			}
+
+			if err := projects.UpsertProject(model.Project{ID: "p1", Name: "holiday"}); err != nil {
+				t.Fatalf("UpsertProject: %v", err)
+			}
+			if err := projects.UpsertProject(model.Project{ID: "p2", Name: "taxes"}); err != nil {
+				t.Fatalf("UpsertProject: %v", err)
+			}
+			if err := projects.UpsertProject(model.Project{ID: "p1", Name: "summer-holiday"}); err != nil {
+				t.Fatalf("UpsertProject existing: %v", err)
+			}
+			if err := projects.DeleteProject("p2"); err != nil {
+				t.Fatalf("DeleteProject: %v", err)
+			}
+			if err := projects.DeleteProject("p2"); !errors.Is(err, storage.ErrNotFound) {
+				t.Fatalf("Expected ErrNotFound deleting twice, got %v", err)
+			}
+
+			got, err := projects.LoadProjects()
+			if err != nil {
+				t.Fatalf("LoadProjects: %v", err)
+			}
+			if len(got) != 1 || got[0].ID != "p1" || got[0].Name != "summer-holiday" {
+				t.Fatalf("Expected only the renamed p1, got %+v", got)
+			}
+
+			if err := b.UpsertTodo(model.Todo{ID: "t1", Content: "Book flights", ProjectID: "p1"}); err != nil {
+				t.Fatalf("UpsertTodo: %v", err)
+			}
+			if todo, _ := b.GetTodo("t1"); todo.ProjectID != "p1" {
+				t.Fatalf("Expected the todo to keep its project, got %+v", todo)
+			}
+		})
+	}
+}
+
+func TestBackendSavedSearches(t *testing.T) {
+	for name, b := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			searches, ok := b.(storage.SavedSearches)
+			if !ok {
+				t.Skip("backend has no saved searches")
+			}
+
+			if err := searches.UpsertSavedSearch(model.SavedSearch{ID: "s1", Name: "Urgent", Query: "tag:urgent -done"}); err != nil {
+				t.Fatalf("UpsertSavedSearch: %v", err)
+			}
+			if err := searches.UpsertSavedSearch(model.SavedSearch{ID: "s2", Name: "Work", Query: "folder:work"}); err != nil {
+				t.Fatalf("UpsertSavedSearch: %v", err)
+			}
+			if err := searches.UpsertSavedSearch(model.SavedSearch{ID: "s1", Name: "Urgent", Query: "tag:urgent is:open"}); err != nil {
+				t.Fatalf("UpsertSavedSearch existing: %v", err)
+			}
+			if err := searches.DeleteSavedSearch("s2"); err != nil {
+				t.Fatalf("DeleteSavedSearch: %v", err)
+			}
+			if err := searches.DeleteSavedSearch("s2"); !errors.Is(err, storage.ErrNotFound) {
+				t.Fatalf("Expected ErrNotFound deleting twice, got %v", err)
+			}
+
+			got, err := searches.LoadSavedSearches()
+			if err != nil {
+				t.Fatalf("LoadSavedSearches: %v", err)
+			}
+			if len(got) != 1 || got[0].ID != "s1" || got[0].Query != "tag:urgent is:open" {
+				t.Fatalf("Expected only the updated s1, got %+v", got)
+			}
+		})
+	}
+}
+
+func TestBackendUpsertFromMerges(t *testing.T) {
+	for name, b := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			v := b.(storage.Versioned)
+			if _, err := v.UpsertNoteFrom(model.Note{}, model.Note{ID: "n1", Title: "Plan", Content: "draft", CreatedAt: time.Now()}); err != nil {
+				t.Fatalf("UpsertNoteFrom a new note: %v", err)
+			}
+			base, err := b.GetNote("n1")
+			if err != nil {
+				t.Fatalf("GetNote: %v", err)
+			}
+
+			// Another writer renames the note while we edit its content.
+			theirs := base
+			theirs.Title = "Plan B"
+			if err := b.UpsertNote(theirs); err != nil {
+				t.Fatalf("UpsertNote: %v", err)
+			}
+			ours := base
+			ours.Content = "final"
+			merged, err := v.UpsertNoteFrom(base, ours)
+			if err != nil || !merged {
+				t.Fatalf("UpsertNoteFrom = %v, %v; want a merge", merged, err)
+			}
+			got, _ := b.GetNote("n1")
+			if got.Title != "Plan B" || got.Content != "final" {
+				t.Fatalf("merged note = %+v, want their title and our content", got)
+			}
+
+			// Both changed the content: nothing is saved.
+			ours = base
+			ours.Content = "mine"
+			if _, err := v.UpsertNoteFrom(base, ours); !errors.Is(err, storage.ErrConflict) {
+				t.Fatalf("UpsertNoteFrom = %v, want ErrConflict", err)
+			}
+			if got, _ := b.GetNote("n1"); got.Content != "final" {
+				t.Fatalf("conflicting save changed the note: %+v", got)
+			}
+
+			// An edit of the note as it is now is a plain save.
+			ours = got
+			ours.Content = "mine"
+			if merged, err := v.UpsertNoteFrom(got, ours); err != nil || merged {
+				t.Fatalf("UpsertNoteFrom = %v, %v; want a plain save", merged, err)
+			}
+
+			// A todo checked off elsewhere keeps that when we raise its priority.
+			if _, err := v.UpsertTodoFrom(model.Todo{}, model.Todo{ID: "t1", Content: "Buy milk", Frequency: model.Once, CreatedAt: time.Now()}); err != nil {
+				t.Fatalf("UpsertTodoFrom a new todo: %v", err)
+			}
+			todo, _ := b.GetTodo("t1")
+			done := todo
+			done.Done = true
+			if err := b.UpsertTodo(done); err != nil {
+				t.Fatalf("UpsertTodo: %v", err)
+			}
+			raised := todo
+			raised.Priority = model.PriorityHigh
+			if merged, err := v.UpsertTodoFrom(todo, raised); err != nil || !merged {
+				t.Fatalf("UpsertTodoFrom = %v, %v; want a merge", merged, err)
+			}
+			if got, _ := b.GetTodo("t1"); !got.Done || got.Priority != model.PriorityHigh {
+				t.Fatalf("merged todo = %+v, want done and high priority", got)
+			}
+
+			// A todo deleted elsewhere is not brought back.
+			if err := b.DeleteTodo("t1"); err != nil {
+				t.Fatalf("DeleteTodo: %v", err)
+			}
+			if _, err := v.UpsertTodoFrom(todo, raised); !errors.Is(err, storage.ErrConflict) {
+				t.Fatalf("UpsertTodoFrom a deleted todo = %v, want ErrConflict", err)
+			}
+		})
+	}
+}
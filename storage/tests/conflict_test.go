@@ -0,0 +1,79 @@
+package storage_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/mtix28/noteme/model"
+	"github.com/mtix28/noteme/storage"
+)
+
+func TestSaveNotesRejectsStaleWrite(t *testing.T) {
+	a, dir := setupTestStorage(t)
+	defer cleanupTestStorage(dir)
+	b, err := storage.NewStorageAt(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a.SaveNotes([]model.Note{{ID: "1", Title: "Base"}})
+	staleA, _ := a.LoadNotes()
+	fresh, _ := b.LoadNotes()
+
+	// b writes first ...
+	fresh = append(fresh, model.Note{ID: "2", Title: "From b"})
+	if err := b.SaveNotes(fresh); err != nil {
+		t.Fatalf("Unexpected error from b: %v", err)
+	}
+
+	// ... so a's save, based on what it read before, must not clobber it.
+	staleA[0].Title = "Edited by a"
+	if err := a.SaveNotes(staleA); !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("Expected ErrConflict, got %v", err)
+	}
+
+	// After reloading, a can save again.
+	reloaded, _ := a.LoadNotes()
+	if len(reloaded) != 2 {
+		t.Fatalf("Expected b's note to be visible, got %+v", reloaded)
+	}
+	if err := a.SaveNotes(reloaded); err != nil {
+		t.Fatalf("Expected save after reload to succeed, got %v", err)
+	}
+}
+
+func TestConcurrentDeletesAreSerialized(t *testing.T) {
+	a, dir := setupTestStorage(t)
+	defer cleanupTestStorage(dir)
+	b, _ := storage.NewStorageAt(dir)
+
+	var todos []model.Todo
+	for i := 0; i < 20; i++ {
+		todos = append(todos, model.Todo{ID: fmt.Sprint(i), Content: "todo"})
+	}
+	a.SaveTodos(todos)
+
+	var wg sync.WaitGroup
+	for i, s := range []*storage.Storage{a, b, a, b} {
+		wg.Add(1)
+		go func(offset int, s *storage.Storage) {
+			defer wg.Done()
+			for j := offset; j < 20; j += 4 {
+				if err := s.DeleteTodo(fmt.Sprint(j)); err != nil {
+					t.Errorf("DeleteTodo: %v", err)
+				}
+			}
+		}(i, s)
+	}
+	wg.Wait()
+
+	left, err := a.LoadTodos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 0 {
+		t.Fatalf("Expected every delete to stick, %d todos left", len(left))
+	}
+}
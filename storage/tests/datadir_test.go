@@ -0,0 +1,50 @@
+package storage_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mtix28/noteme/storage"
+)
+
+func TestDataDirPrecedence(t *testing.T) {
+	// want starts with ~ for paths in the home directory.
+	tests := []struct {
+		name    string
+		flag    string
+		envDir  string
+		xdgHome string
+		legacy  bool // ~/.noteme exists
+		want    string
+	}{
+		{"flag wins", "/from/flag", "/from/env", "/xdg", true, "/from/flag"},
+		{"env over xdg", "", "/from/env", "/xdg", true, "/from/env"},
+		{"xdg", "", "", "/xdg", false, filepath.Join("/xdg", "noteme")},
+		{"existing install over xdg", "", "", "/xdg", true, "~/.noteme"},
+		{"legacy home", "", "", "", false, "~/.noteme"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv(storage.EnvDataDir, tt.envDir)
+			t.Setenv("XDG_DATA_HOME", tt.xdgHome)
+			if tt.legacy {
+				if err := os.Mkdir(filepath.Join(home, ".noteme"), 0o755); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := storage.DataDir(tt.flag)
+			if err != nil {
+				t.Fatalf("DataDir: %v", err)
+			}
+			if want := strings.Replace(tt.want, "~", home, 1); got != want {
+				t.Fatalf("expected %q, got %q", want, got)
+			}
+		})
+	}
+}
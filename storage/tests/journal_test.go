@@ -0,0 +1,122 @@
+package storage_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mtix28/noteme/model"
+	"github.com/mtix28/noteme/storage"
+)
+
+func TestUpsertNoteAppendsToJournal(t *testing.T) {
+	store, dir := setupTestStorage(t)
+	defer cleanupTestStorage(dir)
+
+	store.SaveNotes([]model.Note{{ID: "1", Title: "One"}, {ID: "2", Title: "Two"}})
+	snapshot, _ := os.ReadFile(filepath.Join(dir, storage.NotesFile))
+
+	if err := store.UpsertNote(model.Note{ID: "2", Title: "Two, edited"}); err != nil {
+		t.Fatalf("UpsertNote: %v", err)
+	}
+	if err := store.UpsertNote(model.Note{ID: "3", Title: "Three"}); err != nil {
+		t.Fatalf("UpsertNote: %v", err)
+	}
+
+	after, _ := os.ReadFile(filepath.Join(dir, storage.NotesFile))
+	if string(after) != string(snapshot) {
+		t.Fatal("Expected the snapshot to be left alone by single-item writes")
+	}
+
+	notes, err := store.LoadNotes()
+	if err != nil {
+		t.Fatalf("LoadNotes: %v", err)
+	}
+	var titles []string
+	for _, n := range notes {
+		titles = append(titles, n.Title)
+	}
+	if got := strings.Join(titles, ","); got != "Three,One,Two, edited" {
+		t.Fatalf("Unexpected notes after replay: %s", got)
+	}
+
+	got, err := store.GetNote("2")
+	if err != nil || got.Title != "Two, edited" {
+		t.Fatalf("GetNote: %+v, %v", got, err)
+	}
+}
+
+func TestDeleteTodoIsJournaled(t *testing.T) {
+	store, dir := setupTestStorage(t)
+	defer cleanupTestStorage(dir)
+
+	store.SaveTodos([]model.Todo{{ID: "a"}, {ID: "b"}})
+	if err := store.DeleteTodo("a"); err != nil {
+		t.Fatalf("DeleteTodo: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, storage.TodosLog)); err != nil {
+		t.Fatalf("Expected a journal entry for the delete: %v", err)
+	}
+
+	// A second instance sees the same state.
+	other, _ := storage.NewStorageAt(dir)
+	todos, _ := other.LoadTodos()
+	if len(todos) != 1 || todos[0].ID != "b" {
+		t.Fatalf("Unexpected todos: %+v", todos)
+	}
+}
+
+func TestJournalIsCompacted(t *testing.T) {
+	store, dir := setupTestStorage(t)
+	defer cleanupTestStorage(dir)
+
+	body := strings.Repeat("x", 4096)
+	for i := 0; i < 100; i++ {
+		if err := store.UpsertNote(model.Note{ID: string(rune('A' + i%26)), Title: "n", Content: body}); err != nil {
+			t.Fatalf("UpsertNote: %v", err)
+		}
+	}
+
+	info, err := os.Stat(filepath.Join(dir, storage.NotesLog))
+	if err == nil && info.Size() > 256<<10 {
+		t.Fatalf("Expected the journal to be compacted, it is %d bytes", info.Size())
+	}
+
+	notes, _ := store.LoadNotes()
+	if len(notes) != 26 {
+		t.Fatalf("Expected 26 distinct notes after compaction, got %d", len(notes))
+	}
+}
+
+func TestTornJournalLineIsIgnored(t *testing.T) {
+	store, dir := setupTestStorage(t)
+	defer cleanupTestStorage(dir)
+
+	store.SaveNotes(nil)
+	store.UpsertNote(model.Note{ID: "1", Title: "Kept"})
+
+	f, _ := os.OpenFile(filepath.Join(dir, storage.NotesLog), os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"op":"put","id":"2","item":{"id":"2","tit`)
+	f.Close()
+
+	notes, err := store.LoadNotes()
+	if err != nil {
+		t.Fatalf("Expected a torn last line to be skipped, got %v", err)
+	}
+	if len(notes) != 1 || notes[0].Title != "Kept" {
+		t.Fatalf("Unexpected notes: %+v", notes)
+	}
+}
+
+func TestUpsertIntoEmptyDirDoesNotSeed(t *testing.T) {
+	store, dir := setupTestStorage(t)
+	defer cleanupTestStorage(dir)
+
+	store.UpsertTodo(model.Todo{ID: "mine", Content: "First"})
+
+	todos, _ := store.LoadTodos()
+	if len(todos) != 1 || todos[0].ID != "mine" {
+		t.Fatalf("Expected only the upserted todo, got %+v", todos)
+	}
+}
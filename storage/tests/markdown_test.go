@@ -0,0 +1,140 @@
+package storage_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtix28/noteme/model"
+	"github.com/mtix28/noteme/storage"
+)
+
+func setupMarkdownStorage(t *testing.T) (*storage.MarkdownStorage, string) {
+	dir := t.TempDir()
+	store, err := storage.NewMarkdownStorageAt(dir)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	return store, filepath.Join(dir, storage.NotesDir)
+}
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	store, notesDir := setupMarkdownStorage(t)
+
+	created := time.Date(2026, 3, 14, 15, 9, 26, 0, time.UTC)
+	note := model.Note{
+		ID:        "abc",
+		Title:     "Client Kickoff: Acme",
+		Content:   "---\nnot front matter\n---\n\n# Agenda\n- intro\n",
+		CreatedAt: created,
+		Folder:    "work/clients",
+	}
+	if err := store.SaveNotes([]model.Note{note}); err != nil {
+		t.Fatalf("SaveNotes: %v", err)
+	}
+
+	path := filepath.Join(notesDir, "work", "clients", "client-kickoff-acme.md")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected %s to exist: %v", path, err)
+	}
+	if !strings.HasPrefix(string(data), "---\nid: abc\n") {
+		t.Fatalf("Expected YAML front matter, got:\n%s", data)
+	}
+
+	got, err := store.GetNote("abc")
+	if err != nil {
+		t.Fatalf("GetNote: %v", err)
+	}
+	if got.Title != note.Title || got.Content != note.Content || got.Folder != note.Folder || !got.CreatedAt.Equal(created) {
+		t.Fatalf("Round trip mismatch:\nwant %+v\ngot  %+v", note, got)
+	}
+}
+
+func TestMarkdownRenameMovesFile(t *testing.T) {
+	store, notesDir := setupMarkdownStorage(t)
+
+	store.UpsertNote(model.Note{ID: "1", Title: "Draft", Folder: "ideas"})
+	store.UpsertNote(model.Note{ID: "1", Title: "Final", Folder: "projects"})
+
+	if _, err := os.Stat(filepath.Join(notesDir, "ideas", "draft.md")); !os.IsNotExist(err) {
+		t.Fatal("Expected the old file to be removed")
+	}
+	if _, err := os.Stat(filepath.Join(notesDir, "projects", "final.md")); err != nil {
+		t.Fatalf("Expected the new file: %v", err)
+	}
+
+	// Same title in the same folder gets its own file.
+	store.UpsertNote(model.Note{ID: "2", Title: "Final", Folder: "projects"})
+	if _, err := os.Stat(filepath.Join(notesDir, "projects", "final-2.md")); err != nil {
+		t.Fatalf("Expected a de-duplicated file name: %v", err)
+	}
+}
+
+func TestMarkdownPicksUpOutsideChanges(t *testing.T) {
+	store, notesDir := setupMarkdownStorage(t)
+	store.SaveNotes(nil)
+
+	if changed, _ := store.Changed(); changed {
+		t.Fatal("Expected no changes right after a save")
+	}
+
+	// A file written by another editor, without front matter.
+	os.MkdirAll(filepath.Join(notesDir, "journal"), 0755)
+	if err := os.WriteFile(filepath.Join(notesDir, "journal", "Monday.md"), []byte("Went for a run.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if changed, _ := store.Changed(); !changed {
+		t.Fatal("Expected the new file to be detected")
+	}
+
+	notes, err := store.LoadNotes()
+	if err != nil {
+		t.Fatalf("LoadNotes: %v", err)
+	}
+	if len(notes) != 1 {
+		t.Fatalf("Expected 1 note, got %d", len(notes))
+	}
+	n := notes[0]
+	if n.Title != "Monday" || n.Folder != "journal" || n.Content != "Went for a run.\n" || n.ID == "" {
+		t.Fatalf("Unexpected note from foreign file: %+v", n)
+	}
+
+	// Saving it once gives it front matter but keeps its ID.
+	n.Content = "Went for a long run.\n"
+	if err := store.UpsertNote(n); err != nil {
+		t.Fatalf("UpsertNote: %v", err)
+	}
+	again, _ := store.GetNote(n.ID)
+	if again.Content != n.Content {
+		t.Fatalf("Expected edited content, got %q", again.Content)
+	}
+}
+
+func TestMarkdownTags(t *testing.T) {
+	store, notesDir := setupMarkdownStorage(t)
+
+	if err := store.UpsertNote(model.Note{ID: "1", Title: "Books", Folder: "misc", Tags: []string{"reading", "home"}}); err != nil {
+		t.Fatalf("UpsertNote: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(notesDir, "misc", "books.md"))
+	if !strings.Contains(string(data), "tags:\n") {
+		t.Fatalf("Expected tags in the front matter, got:\n%s", data)
+	}
+
+	// Other tools often write a single tag as a plain string.
+	os.WriteFile(filepath.Join(notesDir, "misc", "film.md"), []byte("---\nid: \"2\"\ntitle: Film\ntags: Movies\n---\nWatch list\n"), 0644)
+
+	for id, want := range map[string]string{"1": "home,reading", "2": "movies"} {
+		n, err := store.GetNote(id)
+		if err != nil {
+			t.Fatalf("GetNote(%s): %v", id, err)
+		}
+		if got := strings.Join(n.Tags, ","); got != want {
+			t.Errorf("Note %s tags = %q, want %q", id, got, want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package storage_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mtix28/noteme/model"
+	"github.com/mtix28/noteme/storage"
+)
+
+func TestLegacyArrayIsMigrated(t *testing.T) {
+	store, dir := setupTestStorage(t)
+	defer cleanupTestStorage(dir)
+
+	path := filepath.Join(dir, storage.NotesFile)
+	legacy := `[{"id": "1", "title": "Old", "content": "", "created_at": "2024-05-01T10:00:00Z", "folder": "general"}]`
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	notes, err := store.LoadNotes()
+	if err != nil {
+		t.Fatalf("LoadNotes: %v", err)
+	}
+	if len(notes) != 1 || notes[0].Title != "Old" {
+		t.Fatalf("Unexpected notes: %+v", notes)
+	}
+
+	upgraded, _ := os.ReadFile(path)
+	if !strings.Contains(string(upgraded), `"version": 1`) {
+		t.Fatalf("Expected the file to be rewritten with a version, got %s", upgraded)
+	}
+	original, err := os.ReadFile(path + ".v0.bak")
+	if err != nil || string(original) != legacy {
+		t.Fatalf("Expected the original to be kept as .v0.bak, got %q, %v", original, err)
+	}
+}
+
+func TestNewerVersionIsRefused(t *testing.T) {
+	store, dir := setupTestStorage(t)
+	defer cleanupTestStorage(dir)
+
+	path := filepath.Join(dir, storage.TodosFile)
+	future := `{"version": 99, "items": [{"id": "1", "content": "from the future", "shiny_new_field": true}]}`
+	if err := os.WriteFile(path, []byte(future), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.LoadTodos(); !errors.Is(err, storage.ErrNewerVersion) {
+		t.Fatalf("Expected ErrNewerVersion, got %v", err)
+	}
+	if err := store.SaveTodos([]model.Todo{{ID: "2"}}); !errors.Is(err, storage.ErrNewerVersion) {
+		t.Fatalf("Expected SaveTodos to refuse, got %v", err)
+	}
+	if err := store.UpsertTodo(model.Todo{ID: "2"}); !errors.Is(err, storage.ErrNewerVersion) {
+		t.Fatalf("Expected UpsertTodo to refuse, got %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != future {
+		t.Fatal("Expected a file from a newer version to be left untouched")
+	}
+}
@@ -0,0 +1,44 @@
+package storage_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mtix28/noteme/model"
+	"github.com/mtix28/noteme/storage"
+)
+
+func TestSQLiteMigratesJSONOnce(t *testing.T) {
+	jsonStore, dir := setupTestStorage(t)
+	defer cleanupTestStorage(dir)
+
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	jsonStore.SaveNotes([]model.Note{{ID: "n1", Title: "Old note", Content: "from json", Folder: "work", CreatedAt: created}})
+	jsonStore.UpsertTodo(model.Todo{ID: "t1", Content: "Old todo", Frequency: model.Weekly})
+
+	store, err := storage.NewSQLiteStorageAt(dir)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	notes, _ := store.LoadNotes()
+	if len(notes) != 1 || notes[0].Folder != "work" || !notes[0].CreatedAt.Equal(created) {
+		t.Fatalf("Expected the JSON note to be migrated, got %+v", notes)
+	}
+	todos, _ := store.LoadTodos()
+	if len(todos) != 1 || todos[0].Frequency != model.Weekly {
+		t.Fatalf("Expected the journaled todo to be migrated, got %+v", todos)
+	}
+
+	// Changes made after the migration are not overwritten on reopen.
+	store.DeleteNote("n1")
+	store.Close()
+
+	store, err = storage.NewSQLiteStorageAt(dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer store.Close()
+	if notes, _ := store.LoadNotes(); len(notes) != 0 {
+		t.Fatalf("Expected the migration to run only once, got %+v", notes)
+	}
+}
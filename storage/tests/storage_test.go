@@ -15,21 +15,7 @@ func setupTestStorage(t *testing.T) (*storage.Storage, string) {
 		t.Fatalf("Failed to create temp dir: %v", err)
 	}
 
-	// We need to hack the storage to use our temp dir
-	// Since NewStorage uses UserHomeDir, we can't easily mock it without dependency injection.
-	// However, for this test, we can just construct the struct manually if the field was exported.
-    // It is not exported.
-    // Let's create a constructor in storage/storage.go that accepts a path for testing purposes,
-    // or just modify the environment variable HOME if running on Linux/Mac, but that's flaky.
-    
-    // Better approach: Let's modify storage.go to allow setting the base path or move the logic to a method we can override?
-    // No, let's just make a NewStorageWithDir for testing or export the struct fields?
-    // Actually, NewStorage uses .noteme in Home.
-    
-    // Simplest for now: set HOME env var to tempDir
-    os.Setenv("HOME", tempDir)
-    
-	store, err := storage.NewStorage()
+	store, err := storage.NewStorageAt(tempDir)
 	if err != nil {
 		t.Fatalf("Failed to create storage: %v", err)
 	}
@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mtix28/noteme/model"
+)
+
+// TrashFile holds deleted notes and todos until they are restored or purged.
+const TrashFile = "trash.json"
+
+// Trash is implemented by backends where DeleteNote and DeleteTodo move the
+// item into a trash collection instead of dropping it.
+type Trash interface {
+	// TrashedItems lists the trash, most recently deleted first.
+	TrashedItems() ([]model.TrashItem, error)
+	// RestoreItem puts a trashed note or todo back where it came from.
+	RestoreItem(id string) error
+	// PurgeItem deletes one trashed item for good, along with a note's
+	// history.
+	PurgeItem(id string) error
+	// PurgeTrash deletes every item trashed before cutoff, as PurgeItem
+	// does, and reports how many there were.
+	PurgeTrash(cutoff time.Time) (int, error)
+}
+
+func (s *Storage) TrashedItems() ([]model.TrashItem, error) {
+	var items []model.TrashItem
+	err := s.withLock(func() (err error) {
+		items, err = s.loadTrash()
+		return err
+	})
+	return items, err
+}
+
+func (s *Storage) RestoreItem(id string) error {
+	return s.withLock(func() error {
+		item, err := s.takeFromTrash(id)
+		if err != nil {
+			return err
+		}
+		if item.Note != nil {
+			return appendEntry(s, NotesFile, journalEntry[model.Note]{Op: opPut, ID: item.ID, Item: item.Note}, s.compactNotes)
+		}
+		return appendEntry(s, TodosFile, journalEntry[model.Todo]{Op: opPut, ID: item.ID, Item: item.Todo}, s.compactTodos)
+	})
+}
+
+func (s *Storage) PurgeItem(id string) error {
+	return s.withLock(func() error {
+		item, err := s.takeFromTrash(id)
+		if err != nil {
+			return err
+		}
+		return s.deleteRevisions(notesIn([]model.TrashItem{item})...)
+	})
+}
+
+func (s *Storage) PurgeTrash(cutoff time.Time) (int, error) {
+	purged := 0
+	err := s.withLock(func() error {
+		items, err := s.loadTrash()
+		if err != nil {
+			return err
+		}
+		kept, expired := splitExpired(items, cutoff)
+		if purged = len(expired); purged == 0 {
+			return nil
+		}
+		if err := saveCollection(s, TrashFile, kept); err != nil {
+			return err
+		}
+		return s.deleteRevisions(notesIn(expired)...)
+	})
+	return purged, err
+}
+
+func (s *Storage) loadTrash() ([]model.TrashItem, error) {
+	items, err := loadCollection(s, TrashFile, trashID)
+	sortTrash(items)
+	return items, err
+}
+
+// moveToTrash records item in the trash. Callers hold the lock.
+func (s *Storage) moveToTrash(item model.TrashItem) error {
+	return appendEntry(s, TrashFile, journalEntry[model.TrashItem]{Op: opPut, ID: item.ID, Item: &item}, compact(s, TrashFile, trashID))
+}
+
+// takeFromTrash removes id from the trash and returns it. Callers hold the
+// lock.
+func (s *Storage) takeFromTrash(id string) (model.TrashItem, error) {
+	items, err := s.loadTrash()
+	if err != nil {
+		return model.TrashItem{}, err
+	}
+	for _, item := range items {
+		if item.ID == id {
+			err := appendEntry(s, TrashFile, journalEntry[model.TrashItem]{Op: opDel, ID: id}, compact(s, TrashFile, trashID))
+			return item, err
+		}
+	}
+	return model.TrashItem{}, ErrNotFound
+}
+
+func trashID(t model.TrashItem) string { return t.ID }
+
+// notesIn lists the IDs of the notes among items.
+func notesIn(items []model.TrashItem) []string {
+	var ids []string
+	for _, item := range items {
+		if item.Note != nil {
+			ids = append(ids, item.ID)
+		}
+	}
+	return ids
+}
+
+func sortTrash(items []model.TrashItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+}
+
+// splitExpired separates items trashed before cutoff from the rest.
+func splitExpired(items []model.TrashItem, cutoff time.Time) (kept, expired []model.TrashItem) {
+	for _, item := range items {
+		if item.DeletedAt.Before(cutoff) {
+			expired = append(expired, item)
+		} else {
+			kept = append(kept, item)
+		}
+	}
+	return kept, expired
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/mtix28/noteme/model"
+)
+
+// Versioned is implemented by backends that can save a single item against
+// the version it was edited from, so that a change another program made to
+// the same item in the meantime is merged or reported instead of
+// overwritten.
+type Versioned interface {
+	// UpsertNoteFrom saves note, edited from base (the zero Note for a new
+	// note). If the stored note is no longer base, the changes on both
+	// sides are merged field by field and merged is true. If both changed
+	// the same field, or the note is gone, it fails with ErrConflict and
+	// saves nothing.
+	UpsertNoteFrom(base, note model.Note) (merged bool, err error)
+	// UpsertTodoFrom is UpsertNoteFrom for todos.
+	UpsertTodoFrom(base, todo model.Todo) (merged bool, err error)
+}
+
+// rebase works out what to save when item, edited from base, replaces
+// stored, the item as it is now (found is false if there is none). Fields
+// only one side changed take that side's value.
+func rebase[T any](base, item, stored T, found bool) (T, bool, error) {
+	var zero T
+	b, err := fieldsOf(base)
+	if err != nil {
+		return zero, false, err
+	}
+	if !found {
+		z, err := fieldsOf(zero)
+		if err != nil {
+			return zero, false, err
+		}
+		if !sameFields(b, z) {
+			return zero, false, ErrConflict // deleted since it was loaded
+		}
+		return item, false, nil
+	}
+	theirs, err := fieldsOf(stored)
+	if err != nil {
+		return zero, false, err
+	}
+	if sameFields(b, theirs) {
+		return item, false, nil
+	}
+	ours, err := fieldsOf(item)
+	if err != nil {
+		return zero, false, err
+	}
+
+	keys := map[string]bool{}
+	for _, fields := range []map[string]json.RawMessage{b, theirs, ours} {
+		for k := range fields {
+			keys[k] = true
+		}
+	}
+	merged := map[string]json.RawMessage{}
+	for k := range keys {
+		var v json.RawMessage
+		switch {
+		case bytes.Equal(ours[k], b[k]):
+			v = theirs[k]
+		case bytes.Equal(theirs[k], b[k]), bytes.Equal(theirs[k], ours[k]):
+			v = ours[k]
+		default:
+			return zero, false, ErrConflict
+		}
+		if v != nil {
+			merged[k] = v
+		}
+	}
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return zero, false, err
+	}
+	var out T
+	err = json.Unmarshal(data, &out)
+	return out, true, err
+}
+
+// fieldsOf is v's JSON encoding by field.
+func fieldsOf(v any) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
+
+func sameFields(a, b map[string]json.RawMessage) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if !bytes.Equal(v, b[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+// findByID returns the item with the given ID.
+func findByID[T any](items []T, id string, idOf func(T) string) (T, bool) {
+	for _, item := range items {
+		if idOf(item) == id {
+			return item, true
+		}
+	}
+	var zero T
+	return zero, false
+}
@@ -0,0 +1,302 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mtix28/noteme/model"
+)
+
+// Folder sidebar: the folders notes are in, as a tree beside the note list,
+// followed by the saved searches. Picking a folder narrows the list to the
+// notes in it and its subfolders; picking a saved search, to the notes that
+// match it. f moves the focus between the sidebar and the list.
+
+// sidebarWidth is the width of the folder sidebar, border included.
+const sidebarWidth = 30
+
+// sidebarRow is a folder, or a saved search when search.ID is set.
+type sidebarRow struct {
+	model.FolderNode
+	search model.SavedSearch
+}
+
+// folderRows is the sidebar: all notes, the folder tree, then the saved
+// searches.
+func (m MainModel) folderRows() []sidebarRow {
+	rows := []sidebarRow{{FolderNode: model.FolderNode{Name: "All notes", Notes: len(m.notes)}}}
+	for _, n := range model.FolderTree(m.notes, m.folderCollapsed) {
+		rows = append(rows, sidebarRow{FolderNode: n})
+	}
+	for _, s := range m.savedSearches {
+		node := model.FolderNode{Name: "⌕ " + s.Name, Notes: m.savedCounts[s.ID].notes}
+		rows = append(rows, sidebarRow{node, s})
+	}
+	return rows
+}
+
+func (m MainModel) updateFolders(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	rows := m.folderRows()
+	m.folderCursor = min(m.folderCursor, len(rows)-1)
+	row := rows[m.folderCursor]
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		m.folderCursor = max(m.folderCursor-1, 0)
+	case key.Matches(msg, m.keys.Down):
+		m.folderCursor = min(m.folderCursor+1, len(rows)-1)
+	case key.Matches(msg, m.keys.Enter), key.Matches(msg, m.keys.Toggle):
+		m.folderFilter, m.noteSearch = row.Path, row.search.ID
+		m.folderFocus = false
+		m.updateNoteListItems()
+		m.noteList.Select(0)
+	case key.Matches(msg, m.keys.Fold):
+		if row.HasChildren {
+			m.folderCollapsed[row.Path] = !m.folderCollapsed[row.Path]
+		}
+	case key.Matches(msg, m.keys.Delete):
+		if row.search.ID != "" {
+			return m.confirmDeleteSavedSearch(row.search.ID)
+		}
+	case key.Matches(msg, m.keys.Rename):
+		if row.Path != "" {
+			m.state = FolderRenameView
+			m.renamingFolder = row.Path
+			m.folderInput.SetValue(row.Path)
+			m.folderInput.CursorEnd()
+			m.folderInput.Focus()
+		}
+	case key.Matches(msg, m.keys.Folders), key.Matches(msg, m.keys.Back):
+		m.folderFocus = false
+	case key.Matches(msg, m.keys.Tab):
+		m.folderFocus = false
+		m.state = TodoListView
+	}
+	return m, nil
+}
+
+func (m MainModel) updateFolderRename(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.state = NoteListView
+		return m, nil
+	case key.Matches(msg, m.keys.Enter):
+		from, to := m.renamingFolder, model.CleanFolder(m.folderInput.Value())
+		if to == "" {
+			m.status = "A folder needs a name"
+			return m, nil
+		}
+		m.state = NoteListView
+		moved := model.RenameFolder(m.notes, from, to)
+		if len(moved) == 0 {
+			return m, nil
+		}
+		if m.folderFilter != "" && model.InFolder(m.folderFilter, from) {
+			m.folderFilter = to + strings.TrimPrefix(m.folderFilter, from)
+		}
+		m.status = fmt.Sprintf("Moved %d notes from %s to %s", len(moved), from, to)
+		return m, m.moveNotesCmd(moved)
+	}
+
+	var cmd tea.Cmd
+	m.folderInput, cmd = m.folderInput.Update(msg)
+	return m, cmd
+}
+
+// openMovePicker lists the folders the selected note can be moved to; n
+// types a new one.
+func (m MainModel) openMovePicker() (tea.Model, tea.Cmd) {
+	item, ok := m.noteList.SelectedItem().(noteItem)
+	if !ok {
+		return m, nil
+	}
+	counts := map[string]int{}
+	for _, n := range model.FolderTree(m.notes, nil) {
+		counts[n.Path] = n.Notes
+	}
+	folders := model.Folders(m.notes)
+	items := make([]list.Item, len(folders))
+	selected := 0
+	for i, f := range folders {
+		items[i] = folderItem{f, counts[f]}
+		if f == model.CleanFolder(item.note.Folder) {
+			selected = i
+		}
+	}
+	m.movingNote = item.note
+	m.folderPicker.Title = "Move \"" + item.note.Title + "\" to"
+	m.folderPicker.SetItems(items)
+	m.folderPicker.ResetFilter()
+	m.folderPicker.Select(selected)
+	m.state = MoveNoteView
+	return m, nil
+}
+
+func (m MainModel) updateMovePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.state = NoteListView
+		return m, nil
+	case key.Matches(msg, m.keys.Enter):
+		item, ok := m.folderPicker.SelectedItem().(folderItem)
+		if !ok {
+			return m, nil
+		}
+		return m.moveNoteTo(item.path)
+	case key.Matches(msg, m.keys.New):
+		// Start from the highlighted folder, to make a subfolder of it.
+		m.state = MoveNewFolderView
+		m.folderInput.SetValue("")
+		if item, ok := m.folderPicker.SelectedItem().(folderItem); ok && item.path != "" {
+			m.folderInput.SetValue(item.path + "/")
+		}
+		m.folderInput.CursorEnd()
+		m.folderInput.Focus()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.folderPicker, cmd = m.folderPicker.Update(msg)
+	return m, cmd
+}
+
+// updateMoveNewFolder takes the path of a folder that doesn't exist yet to
+// move the note to.
+func (m MainModel) updateMoveNewFolder(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.state = MoveNoteView
+		return m, nil
+	case key.Matches(msg, m.keys.Enter):
+		folder := model.CleanFolder(m.folderInput.Value())
+		if folder == "" {
+			m.status = "A folder needs a name"
+			return m, nil
+		}
+		return m.moveNoteTo(folder)
+	}
+
+	var cmd tea.Cmd
+	m.folderInput, cmd = m.folderInput.Update(msg)
+	return m, cmd
+}
+
+// moveNoteTo moves the note being moved to folder and goes back to the
+// notes.
+func (m MainModel) moveNoteTo(folder string) (tea.Model, tea.Cmd) {
+	m.state = NoteListView
+	note := m.movingNote
+	if model.CleanFolder(note.Folder) == folder {
+		return m, nil
+	}
+	note.Folder = folder
+	m.status = "Moved \"" + note.Title + "\" to " + folder
+	return m, m.moveNotesCmd([]model.Note{note})
+}
+
+// moveNotesCmd saves notes that moved folder one after the other, recording
+// each in its history.
+func (m MainModel) moveNotesCmd(notes []model.Note) tea.Cmd {
+	bases := make([]model.Note, len(notes))
+	for i, note := range notes {
+		bases[i] = m.noteBase[note.ID]
+		m.noteBase[note.ID] = note
+	}
+	return func() tea.Msg {
+		var moved noteSavedMsg
+		for i, note := range notes {
+			msg := m.saveNote(bases[i], note)
+			if msg.err != nil {
+				return msg
+			}
+			moved.merged = moved.merged || msg.merged
+			moved.clashed = moved.clashed || msg.clashed
+		}
+		return moved
+	}
+}
+
+// acceptFolderSuggestion completes the folder being typed in the editor
+// from the folders in use, if one matches.
+func (m *MainModel) acceptFolderSuggestion() bool {
+	s := m.noteFolderInput.CurrentSuggestion()
+	if s == "" || len(s) <= len(m.noteFolderInput.Value()) {
+		return false
+	}
+	m.noteFolderInput.SetValue(s)
+	m.noteFolderInput.CursorEnd()
+	return true
+}
+
+// renderFolders draws the sidebar, scrolled to keep the cursor in view.
+func (m MainModel) renderFolders(height int) string {
+	rows := m.folderRows()
+	width := sidebarWidth - sidebarStyle.GetHorizontalFrameSize() - 1 // a gap before the border
+	height = max(height, 1)
+	fit := height - 2 // below the title
+	if len(m.savedSearches) > 0 {
+		fit -= 2 // the saved searches heading
+	}
+	fit = max(fit, 1)
+	first := max(0, min(m.folderCursor-fit/2, len(rows)-fit))
+
+	lines := []string{titleStyle.Render("Folders")}
+	for i := first; i < len(rows) && len(lines) < height-1; i++ {
+		row := rows[i]
+		if row.search.ID != "" && (i == 0 || rows[i-1].search.ID == "") {
+			lines = append(lines, "", titleStyle.Render("Saved searches"))
+			if len(lines) >= height-1 {
+				break
+			}
+		}
+		marker := "  "
+		if row.HasChildren && m.folderCollapsed[row.Path] {
+			marker = "▸ "
+		} else if row.HasChildren {
+			marker = "▾ "
+		}
+		count := fmt.Sprintf(" %d", row.Notes)
+		name := truncate(strings.Repeat("  ", row.Depth)+marker+row.Name, width-len(count))
+		line := name + strings.Repeat(" ", max(width-len([]rune(name))-len(count), 0)) + count
+
+		switch {
+		case m.folderFocus && i == m.folderCursor:
+			line = folderCursorStyle.Render(line)
+		case row.search.ID == m.noteSearch && row.Path == m.folderFilter:
+			line = folderActiveStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return sidebarStyle.Height(height).Render(strings.Join(lines, "\n"))
+}
+
+func truncate(s string, width int) string {
+	r := []rune(s)
+	if len(r) <= width {
+		return s
+	}
+	if width < 1 {
+		return ""
+	}
+	return string(r[:width-1]) + "…"
+}
+
+func (m MainModel) folderHelp() []key.Binding {
+	pick := m.keys.Enter
+	pick.SetHelp("enter", "show folder")
+	del := m.keys.Delete
+	del.SetHelp("d", "delete saved search")
+	return []key.Binding{m.keys.Up, m.keys.Down, pick, m.keys.Fold, m.keys.Rename, del, m.keys.Folders, m.keys.Quit}
+}
+
+type folderItem struct {
+	path  string
+	notes int
+}
+
+func (f folderItem) FilterValue() string { return f.path }
+func (f folderItem) Title() string       { return f.path }
+func (f folderItem) Description() string { return fmt.Sprintf("%d notes", f.notes) }
@@ -0,0 +1,190 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/google/uuid"
+	"github.com/mtix28/noteme/diff"
+	"github.com/mtix28/noteme/model"
+	"github.com/mtix28/noteme/storage"
+)
+
+// Revision history of the selected note: a list of saved versions on the
+// left, and on the right the diff from the highlighted version to the
+// current one.
+
+type revisionsLoadedMsg struct {
+	revisions []model.Revision
+	err       error
+}
+
+func (m MainModel) openHistory() (tea.Model, tea.Cmd) {
+	item, ok := m.noteList.SelectedItem().(noteItem)
+	if !ok {
+		return m, nil
+	}
+	history, ok := m.store.(storage.History)
+	if !ok {
+		m.status = "This storage backend does not keep note history"
+		return m, nil
+	}
+	m.historyNote = item.note
+	return m, func() tea.Msg {
+		revs, err := history.Revisions(item.note.ID)
+		return revisionsLoadedMsg{revs, err}
+	}
+}
+
+func (m MainModel) updateHistory(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.state = NoteListView
+		return m, nil
+	case key.Matches(msg, m.keys.Restore):
+		item, ok := m.historyList.SelectedItem().(revisionItem)
+		if !ok {
+			return m, nil
+		}
+		restored := item.rev.Apply(m.historyNote)
+		m.state = NoteListView
+		m.status = "Restored version from " + item.rev.SavedAt.Format("Jan 02 15:04")
+		return m, m.upsertNoteCmd(restored)
+	case msg.String() == "pgup", msg.String() == "pgdown":
+		var cmd tea.Cmd
+		m.historyDiff, cmd = m.historyDiff.Update(msg)
+		return m, cmd
+	}
+
+	var cmd tea.Cmd
+	m.historyList, cmd = m.historyList.Update(msg)
+	m.refreshHistoryDiff()
+	return m, cmd
+}
+
+func (m *MainModel) setRevisions(revs []model.Revision) {
+	items := make([]list.Item, len(revs))
+	for i, r := range revs {
+		items[i] = revisionItem{rev: r, latest: i == 0}
+	}
+	m.historyList.SetItems(items)
+	m.historyList.Select(0)
+	m.historyList.Title = "History: " + m.historyNote.Title
+	m.historyDiffs = map[string]string{}
+	m.historyShown = ""
+	m.refreshHistoryDiff()
+}
+
+// refreshHistoryDiff shows the diff for the highlighted revision. Diffs are
+// worked out once per revision while the history is open, and the view is
+// left alone (scroll included) until another revision is highlighted.
+func (m *MainModel) refreshHistoryDiff() {
+	item, ok := m.historyList.SelectedItem().(revisionItem)
+	if !ok {
+		m.historyDiff.SetContent(emptyStateStyle.Render("No saved versions yet."))
+		return
+	}
+	if item.rev.ID == m.historyShown {
+		return
+	}
+	d, ok := m.historyDiffs[item.rev.ID]
+	if !ok {
+		current := model.NewRevision("", m.historyNote, time.Time{})
+		d = diff.Unified(
+			item.rev.SavedAt.Format("2006-01-02 15:04:05"), "current",
+			revisionText(item.rev), revisionText(current), 3,
+		)
+		if d == "" {
+			d = emptyStateStyle.Render("Identical to the current version.")
+		} else {
+			d = colorDiff(d)
+		}
+		m.historyDiffs[item.rev.ID] = d
+	}
+	m.historyShown = item.rev.ID
+	m.historyDiff.SetContent(d)
+	m.historyDiff.GotoTop()
+}
+
+func (m MainModel) renderHistory() string {
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		m.historyList.View(),
+		diffBoxStyle.Render(m.historyDiff.View()),
+	)
+}
+
+// revisionText is what gets diffed: the body with the title and folder on
+// top, so renames and moves show up too.
+func revisionText(r model.Revision) string {
+	return "Title: " + r.Title + "\nFolder: " + r.Folder + "\n\n" + r.Content
+}
+
+func colorDiff(d string) string {
+	lines := strings.Split(strings.TrimSuffix(d, "\n"), "\n")
+	for i, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "+++"), strings.HasPrefix(l, "---"), strings.HasPrefix(l, "@@"):
+			lines[i] = diffMetaStyle.Render(l)
+		case strings.HasPrefix(l, "+"):
+			lines[i] = diffInsertStyle.Render(l)
+		case strings.HasPrefix(l, "-"):
+			lines[i] = diffDeleteStyle.Render(l)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// recordRevision saves the current state of n to the history, if the
+// backend keeps one.
+func (m MainModel) recordRevision(n model.Note) error {
+	history, ok := m.store.(storage.History)
+	if !ok {
+		return nil
+	}
+	return history.AddRevision(model.NewRevision(uuid.New().String(), n, time.Now()))
+}
+
+// recordOriginal saves the stored version of the note with the given ID to
+// the history if it has none there yet, as with the welcome note or notes
+// from before history was kept, so that the first edit doesn't lose it.
+// Call it before saving the edited note.
+func (m MainModel) recordOriginal(id string) error {
+	history, ok := m.store.(storage.History)
+	if !ok {
+		return nil
+	}
+	revs, err := history.Revisions(id)
+	if err != nil || len(revs) > 0 {
+		return err
+	}
+	n, err := m.store.GetNote(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil // a new note
+	}
+	if err != nil {
+		return err
+	}
+	return history.AddRevision(model.NewRevision(uuid.New().String(), n, time.Now()))
+}
+
+type revisionItem struct {
+	rev    model.Revision
+	latest bool
+}
+
+func (r revisionItem) FilterValue() string { return r.rev.Title }
+func (r revisionItem) Title() string {
+	title := r.rev.SavedAt.Format("2006-01-02 15:04:05")
+	if r.latest {
+		title += " (latest)"
+	}
+	return title
+}
+func (r revisionItem) Description() string {
+	return "[" + r.rev.Folder + "] " + r.rev.Title
+}
@@ -17,6 +17,28 @@ type KeyMap struct {
 	Tab      key.Binding
 	Toggle   key.Binding
     Delete   key.Binding
+	History  key.Binding
+	Restore  key.Binding
+	Edit     key.Binding
+	Tags     key.Binding
+	TagMode  key.Binding
+	Clear    key.Binding
+	Raise    key.Binding
+	Lower    key.Binding
+	Sort     key.Binding
+	AddSub   key.Binding
+	Indent   key.Binding
+	Outdent  key.Binding
+	MoveUp   key.Binding
+	MoveDown key.Binding
+	Fold     key.Binding
+	Folders  key.Binding
+	Move     key.Binding
+	Rename   key.Binding
+	Search   key.Binding
+	Filter   key.Binding
+	Saved    key.Binding
+	Read     key.Binding
 }
 
 func NewKeyMap() KeyMap {
@@ -77,5 +99,93 @@ func NewKeyMap() KeyMap {
             key.WithKeys("d", "x"),
             key.WithHelp("d/x", "delete"),
         ),
+		History: key.NewBinding(
+			key.WithKeys("H"),
+			key.WithHelp("H", "history"),
+		),
+		Restore: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "restore version"),
+		),
+		Edit: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "edit"),
+		),
+		Tags: key.NewBinding(
+			key.WithKeys("#"),
+			key.WithHelp("#", "tags"),
+		),
+		TagMode: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "any/all"),
+		),
+		Clear: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "clear filter"),
+		),
+		Raise: key.NewBinding(
+			key.WithKeys("+", "="),
+			key.WithHelp("+", "raise priority"),
+		),
+		Lower: key.NewBinding(
+			key.WithKeys("-"),
+			key.WithHelp("-", "lower priority"),
+		),
+		Sort: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "sort"),
+		),
+		AddSub: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "add subtask"),
+		),
+		Indent: key.NewBinding(
+			key.WithKeys(">"),
+			key.WithHelp(">/<", "indent/outdent"),
+		),
+		Outdent: key.NewBinding(
+			key.WithKeys("<"),
+			key.WithHelp("<", "outdent"),
+		),
+		MoveUp: key.NewBinding(
+			key.WithKeys("K", "shift+up"),
+			key.WithHelp("K/J", "move up/down"),
+		),
+		MoveDown: key.NewBinding(
+			key.WithKeys("J", "shift+down"),
+			key.WithHelp("J", "move down"),
+		),
+		Fold: key.NewBinding(
+			key.WithKeys("z"),
+			key.WithHelp("z", "fold"),
+		),
+		Folders: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "folders"),
+		),
+		Move: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "move"),
+		),
+		Rename: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "rename folder"),
+		),
+		Search: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "search"),
+		),
+		Filter: key.NewBinding(
+			key.WithKeys("ctrl+f"),
+			key.WithHelp("ctrl+f", "filter list"),
+		),
+		Saved: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "saved searches"),
+		),
+		Read: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "read"),
+		),
 	}
 }
@@ -1,11 +1,14 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/mtix28/noteme/model"
+	"github.com/mtix28/noteme/query"
+	"github.com/mtix28/noteme/search"
 	"github.com/mtix28/noteme/storage"
 
 	"github.com/charmbracelet/bubbles/help"
@@ -13,7 +16,9 @@ import (
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/google/uuid"
 )
@@ -27,17 +32,30 @@ const (
 	TodoListView
 	TodoAddView
     DeleteConfirmView
+	HistoryView
+	TrashView
+	TagView
+	ProjectView
+	ProjectAddView
+	MoveNoteView
+	MoveNewFolderView
+	FolderRenameView
+	SearchView
+	SaveSearchView
+	SavedSearchView
+	NoteReadView
 )
 
 type MainModel struct {
 	state  sessionState
-	store  *storage.Storage
+	store  storage.Backend
 	width  int
 	height int
 
 	// Data
-	notes []model.Note
-	todos []model.Todo
+	notes    []model.Note
+	todos    []model.Todo
+	projects []model.Project
 
 	// Components
 	noteList list.Model
@@ -50,21 +68,90 @@ type MainModel struct {
 	noteFolderInput  textinput.Model
 	noteContentInput textarea.Model
 	currentNoteID    string
+	notePreview      viewport.Model // live Markdown preview on wide terminals
+
+	// Note rendered as Markdown to read
+	readingNote model.Note
+	noteReader  viewport.Model
+	markdown    map[int]*glamour.TermRenderer // by wrap width, see markdownRenderer
 
 	// Todo Input
-	todoInput textinput.Model
+	todoInput     textinput.Model
+	editingTodoID string // empty when adding a new todo
+	todoSort      todoSort
+	newTodoParent model.Todo // set when adding a subtask
+	collapsed     map[string]bool // todos whose subtasks are folded
+
+	// Note history
+	historyNote model.Note
+	historyList list.Model
+	historyDiff viewport.Model
+	// historyDiffs caches the rendered diff from each revision, by ID, to
+	// historyNote, and historyShown is the revision in historyDiff.
+	historyDiffs map[string]string
+	historyShown string
+
+	// Trash
+	trash     []model.TrashItem
+	trashList list.Model
+
+	// Tag browser and the tag filter applied to the note and todo lists
+	tagList     list.Model
+	tagOrigin   sessionState
+	tagPicked   map[string]bool
+	tagPickAll  bool
+	tagFilter   []string
+	tagMatchAll bool
+
+	// Projects, and the project the todo list is narrowed to
+	projectList   list.Model
+	projectInput  textinput.Model
+	projectFilter string
+
+	// Folder sidebar beside the note list, and the folder the list is
+	// narrowed to
+	folderFocus     bool
+	folderCursor    int
+	folderFilter    string
+	folderCollapsed map[string]bool
+	folderPicker    list.Model // where to move a note
+	movingNote      model.Note
+	folderInput     textinput.Model // new name of a folder, or new folder to move a note to
+	renamingFolder  string
+
+	// Search over every note and todo
+	searchIndex   *search.Index
+	searchInput   textinput.Model
+	searchResults []search.Result
+	searchErr     error // why the query does not parse
+	searchCursor  int
+	searchOrigin  sessionState
+
+	// Saved searches: smart folders in the sidebar and filters for the
+	// todo list, with how many notes and todos each matches
+	savedSearches     []model.SavedSearch
+	savedCounts       map[string]savedCount
+	noteSearch        string // ID of the saved search the note list is narrowed to
+	todoSearch        string // ID of the saved search the todo list is narrowed to
+	savedList         list.Model
+	saveSearchInput   textinput.Model
+	savedDeleteOrigin sessionState
     
     // Deletion State
     itemToDeleteID   string
-    itemToDeleteType string // "note" or "todo"
-}
+    itemToDeleteType string // "note", "todo" or itemTypeTrashed
 
-func NewModel() (MainModel, error) {
-	store, err := storage.NewStorage()
-	if err != nil {
-		return MainModel{}, err
-	}
+	// One-line feedback shown above the help, cleared on the next key press
+	status string
+
+	// Each note and todo as the store had it when last loaded or saved from
+	// here: the version the next save of it was edited from
+	noteBase map[string]model.Note
+	todoBase map[string]model.Todo
+}
 
+// NewModel builds the root model on top of any storage backend.
+func NewModel(store storage.Backend) MainModel {
 	// Notes List
 	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
 	l.Title = "Notes"
@@ -72,44 +159,122 @@ func NewModel() (MainModel, error) {
     l.DisableQuitKeybindings()
 
 	// Todo List
-	tl := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	tl := list.New([]list.Item{}, newTodoDelegate(), 0, 0)
 	tl.Title = "Todos"
     tl.SetShowHelp(false)
     tl.DisableQuitKeybindings()
 
+	// History
+	hl := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	hl.SetShowHelp(false)
+	hl.SetFilteringEnabled(false)
+	hl.DisableQuitKeybindings()
+
+	// Trash
+	trl := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	trl.Title = "Trash"
+	trl.SetShowHelp(false)
+	trl.DisableQuitKeybindings()
+
+	// Tags
+	tgl := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	tgl.Title = "Tags"
+	tgl.SetShowHelp(false)
+	tgl.DisableQuitKeybindings()
+
+	// Projects
+	pl := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	pl.Title = "Projects"
+	pl.SetShowHelp(false)
+	pl.DisableQuitKeybindings()
+
+	pi := textinput.New()
+	pi.Placeholder = "Project name (e.g. holiday)"
+
+	// Folders
+	fp := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	fp.SetShowHelp(false)
+	fp.DisableQuitKeybindings()
+
+	fri := textinput.New()
+	fri.Placeholder = "Folder (e.g. work/clients)"
+
+	// Search
+	si := textinput.New()
+	si.Placeholder = `Search, e.g. budget folder:work tag:urgent -is:done created:>2026-01-01 "exact phrase"`
+
+	// Saved searches
+	ssl := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	ssl.Title = "Saved Searches"
+	ssl.SetShowHelp(false)
+	ssl.DisableQuitKeybindings()
+
+	ssi := textinput.New()
+	ssi.Placeholder = "Name (e.g. Urgent at work)"
+
+	// The lists filter on their own key; / is search.
+	keys := NewKeyMap()
+	for _, list := range []*list.Model{&l, &tl, &trl, &tgl, &pl, &fp, &ssl} {
+		list.KeyMap.Filter = keys.Filter
+	}
+
 	// Editor
 	ti := textinput.New()
 	ti.Placeholder = "Note Title"
 	ti.Focus()
 
 	fi := textinput.New()
-	fi.Placeholder = "Folder (e.g. daily, work)"
+	fi.Placeholder = "Folder (e.g. daily, work/clients)"
+	fi.ShowSuggestions = true
 
 	ta := textarea.New()
 	ta.Placeholder = "Start typing your note..."
 
 	// Todo Input
 	tdi := textinput.New()
-	tdi.Placeholder = "New Todo... (ends with /daily, /weekly for frequency)"
+	tdi.Placeholder = "New Todo... (try: water plants every 3 days due tomorrow)"
 
 	return MainModel{
 		state:            DashboardView,
 		store:            store,
 		noteList:         l,
 		todoList:         tl,
-        keys:             NewKeyMap(),
+        keys:             keys,
         help:             help.New(),
 		noteTitleInput:   ti,
 		noteFolderInput:  fi,
 		noteContentInput: ta,
 		todoInput:        tdi,
-	}, nil
+		historyList:      hl,
+		historyDiff:      viewport.New(0, 0),
+		notePreview:      viewport.New(0, 0),
+		noteReader:       viewport.New(0, 0),
+		trashList:        trl,
+		tagList:          tgl,
+		projectList:      pl,
+		projectInput:     pi,
+		collapsed:        map[string]bool{},
+		folderPicker:     fp,
+		folderInput:      fri,
+		folderCollapsed:  map[string]bool{},
+		searchIndex:      search.NewIndex(nil),
+		searchInput:      si,
+		savedList:        ssl,
+		saveSearchInput:  ssi,
+		noteBase:         map[string]model.Note{},
+		todoBase:         map[string]model.Todo{},
+	}
 }
 
 func (m MainModel) Init() tea.Cmd {
 	return tea.Batch(
 		m.loadNotesCmd,
 		m.loadTodosCmd,
+		m.loadTrashCmd,
+		m.loadProjectsCmd,
+		m.loadSavedSearchesCmd,
+		m.watchCmd(),
+		periodTickCmd(),
 	)
 }
 
@@ -119,10 +284,20 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-        // Always allow quitting
-        if key.Matches(msg, m.keys.Quit) {
+        // While a list's filter prompt is open, keys are filter text.
+        if m.listFiltering() && msg.String() != "ctrl+c" {
+            break
+        }
+        // Always allow quitting, though q is just a letter while typing
+        if key.Matches(msg, m.keys.Quit) && (!m.typing() || msg.Type == tea.KeyCtrlC) {
             return m, tea.Quit
         }
+		m.status = ""
+
+        // Search is one key away from every list.
+        if key.Matches(msg, m.keys.Search) && m.showsList() {
+            return m.openSearch()
+        }
 
 		// State specific handling
 		switch m.state {
@@ -138,31 +313,40 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
                  return m.startNewNote()
             case msg.String() == "t": // Lowercase t
                  return m.startNewTodo()
+            case key.Matches(msg, m.keys.Tags):
+                return m.openTags(NoteListView)
             }
 
 		case NoteListView:
+            if m.folderFocus {
+                return m.updateFolders(msg)
+            }
 			switch {
             case key.Matches(msg, m.keys.Tab):
                 m.state = TodoListView
+            case key.Matches(msg, m.keys.Folders):
+                m.folderFocus = true
+                return m, nil
+            case key.Matches(msg, m.keys.Move):
+                return m.openMovePicker()
+            case key.Matches(msg, m.keys.Read):
+                return m.openReader()
 			case key.Matches(msg, m.keys.New):
                 return m.startNewNote()
             case key.Matches(msg, m.keys.Delete):
-                if m.noteList.SelectedItem() != nil {
-                    item := m.noteList.SelectedItem().(noteItem)
+                if item, ok := m.noteList.SelectedItem().(noteItem); ok {
                     m.itemToDeleteID = item.note.ID
                     m.itemToDeleteType = "note"
                     m.state = DeleteConfirmView
                     return m, nil
                 }
+            case key.Matches(msg, m.keys.History):
+				return m.openHistory()
+            case key.Matches(msg, m.keys.Tags):
+				return m.openTags(NoteListView)
             case key.Matches(msg, m.keys.Enter):
-				if m.noteList.SelectedItem() != nil {
-					item := m.noteList.SelectedItem().(noteItem)
-					m.state = NoteEditView
-					m.currentNoteID = item.note.ID
-					m.noteTitleInput.SetValue(item.note.Title)
-					m.noteFolderInput.SetValue(item.note.Folder)
-					m.noteContentInput.SetValue(item.note.Content)
-					m.noteTitleInput.Focus()
+				if item, ok := m.noteList.SelectedItem().(noteItem); ok {
+					return m.editNote(item.note)
 				}
             }
 
@@ -173,6 +357,9 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case key.Matches(msg, m.keys.Save):
 				return m, m.saveNoteCmd()
 			case key.Matches(msg, m.keys.Tab):
+				if m.noteFolderInput.Focused() && m.acceptFolderSuggestion() {
+					return m, nil
+				}
 				if m.noteTitleInput.Focused() {
 					m.noteTitleInput.Blur()
 					m.noteFolderInput.Focus()
@@ -188,30 +375,61 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case TodoListView:
 			switch {
             case key.Matches(msg, m.keys.Tab):
-                m.state = DashboardView
+                switch {
+                case m.hasProjects():
+                    m.state = ProjectView
+                case m.hasTrash():
+                    m.state = TrashView
+                default:
+                    m.state = DashboardView
+                }
+            case key.Matches(msg, m.keys.Back) && m.projectFilter != "" && m.todoList.FilterState() == list.Unfiltered:
+                // Leave the project drilled into from the projects view.
+                m.projectFilter = ""
+                m.updateTodoListItems()
+                m.state = ProjectView
+                return m, nil
 			case key.Matches(msg, m.keys.New):
                 return m.startNewTodo()
+            case key.Matches(msg, m.keys.Edit):
+                if item, ok := m.todoList.SelectedItem().(todoItem); ok {
+                    return m.startEditTodo(item.todo)
+                }
+            case key.Matches(msg, m.keys.Tags):
+                return m.openTags(TodoListView)
+            case key.Matches(msg, m.keys.Saved):
+                return m.openSavedSearches()
+            case key.Matches(msg, m.keys.Raise):
+                return m.shiftPriority(true)
+            case key.Matches(msg, m.keys.Lower):
+                return m.shiftPriority(false)
+            case key.Matches(msg, m.keys.Sort):
+                m.todoSort = (m.todoSort + 1) % (sortByHand + 1)
+                m.updateTodoListItems()
+                m.todoList.Select(0)
+            case key.Matches(msg, m.keys.AddSub):
+                if i := m.selectedTodoIndex(); i >= 0 {
+                    return m.startNewSubtask(m.todos[i])
+                }
+            case key.Matches(msg, m.keys.Indent):
+                return m.reshapeTodos(model.IndentTodo)
+            case key.Matches(msg, m.keys.Outdent):
+                return m.reshapeTodos(model.OutdentTodo)
+            case key.Matches(msg, m.keys.MoveUp):
+                return m.moveTodo(-1)
+            case key.Matches(msg, m.keys.MoveDown):
+                return m.moveTodo(1)
+            case key.Matches(msg, m.keys.Fold):
+                return m.toggleFold()
             case key.Matches(msg, m.keys.Delete):
-                if m.todoList.SelectedItem() != nil {
-                     // Get the actual todo item - since we filter or sort, using index might be unsafe if we add filters later,
-                     // but currently m.todoList items match m.todos unless filtered. 
-                     // Ideally we should use the SelectedItem()
-                     item, ok := m.todoList.SelectedItem().(todoItem)
-                     if ok {
-                         m.itemToDeleteID = item.todo.ID
-                         m.itemToDeleteType = "todo"
-                         m.state = DeleteConfirmView
-                         return m, nil
-                     }
+                if item, ok := m.todoList.SelectedItem().(todoItem); ok {
+                    m.itemToDeleteID = item.todo.ID
+                    m.itemToDeleteType = "todo"
+                    m.state = DeleteConfirmView
+                    return m, nil
                 }
             case key.Matches(msg, m.keys.Toggle), key.Matches(msg, m.keys.Enter):
-				if m.todoList.SelectedItem() != nil {
-					idx := m.todoList.Index()
-					if idx >= 0 && idx < len(m.todos) {
-						m.todos[idx].Done = !m.todos[idx].Done
-						return m, m.saveTodosCmd()
-					}
-				}
+				return m.toggleTodo()
             }
 
 		case TodoAddView:
@@ -220,44 +438,65 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.state = TodoListView
 			case key.Matches(msg, m.keys.Enter):
 				text := m.todoInput.Value()
-				if text != "" {
-					freq := model.Once
-					content := text
-					if strings.HasSuffix(text, "/daily") {
-						freq = model.Daily
-						content = strings.TrimSpace(strings.TrimSuffix(text, "/daily"))
-					} else if strings.HasSuffix(text, "/weekly") {
-						freq = model.Weekly
-						content = strings.TrimSpace(strings.TrimSuffix(text, "/weekly"))
-					} else if strings.HasSuffix(text, "/monthly") {
-						freq = model.Monthly
-						content = strings.TrimSpace(strings.TrimSuffix(text, "/monthly"))
-					}
-
-					newTodo := model.Todo{
-						ID:        uuid.New().String(),
-						Content:   content,
-						Done:      false,
-						CreatedAt: time.Now(),
-						Frequency: freq,
+				if strings.TrimSpace(text) != "" {
+					newTodo, project, err := parseTodoInput(text, time.Now())
+					if err != nil {
+						m.status = err.Error()
+						return m, nil
 					}
-					m.todos = append([]model.Todo{newTodo}, m.todos...)
 					m.state = TodoListView
-					return m, m.saveTodosCmd()
+					projectID, saveProject := m.projectFor(project)
+					newTodo.ProjectID = projectID
+					if m.editingTodoID != "" {
+						return m, tea.Batch(saveProject, m.updateTodo(newTodo))
+					}
+					return m, tea.Batch(saveProject, m.addTodo(newTodo))
 				}
 			}
 
+		case HistoryView:
+			return m.updateHistory(msg)
+
+		case TrashView:
+			return m.updateTrash(msg)
+
+		case TagView:
+			return m.updateTags(msg)
+
+		case ProjectView:
+			return m.updateProjects(msg)
+
+		case ProjectAddView:
+			return m.updateProjectAdd(msg)
+
+		case MoveNoteView:
+			return m.updateMovePicker(msg)
+
+		case MoveNewFolderView:
+			return m.updateMoveNewFolder(msg)
+
+		case FolderRenameView:
+			return m.updateFolderRename(msg)
+
+		case SearchView:
+			return m.updateSearch(msg)
+
+		case SaveSearchView:
+			return m.updateSaveSearch(msg)
+
+		case SavedSearchView:
+			return m.updateSavedSearches(msg)
+
+		case NoteReadView:
+			return m.updateReader(msg)
+
 		case DeleteConfirmView:
             switch {
             case key.Matches(msg, m.keys.Enter) || msg.String() == "y":
                 return m, m.deleteItemCmd()
             case key.Matches(msg, m.keys.Back) || msg.String() == "n":
                  // Return to previous view
-                 if m.itemToDeleteType == "note" {
-                     m.state = NoteListView
-                 } else {
-                     m.state = TodoListView
-                 }
+                 m.state = m.deleteOrigin()
                  return m, nil
             }
         }
@@ -272,33 +511,149 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
         availableWidth := msg.Width - h
         availableHeight := msg.Height - v
         
-        m.noteList.SetSize(availableWidth, availableHeight - 3) // leave room for help
+        m.noteList.SetSize(availableWidth - sidebarWidth, availableHeight - 3) // leave room for help and the folder sidebar
 		m.todoList.SetSize(availableWidth, availableHeight - 3)
-		m.noteContentInput.SetWidth(availableWidth)
-		m.noteContentInput.SetHeight(availableHeight - 10)
+		m.trashList.SetSize(availableWidth, availableHeight-3)
+		m.tagList.SetSize(availableWidth, availableHeight-3)
+		m.projectList.SetSize(availableWidth, availableHeight-3)
+		m.folderPicker.SetSize(availableWidth, availableHeight-3)
+		m.savedList.SetSize(availableWidth, availableHeight-3)
+		m.markdown = nil // new widths, new renderers
+		m.resizeEditor(availableWidth, availableHeight)
+		m.noteReader.Width = availableWidth
+		m.noteReader.Height = availableHeight - 6 // below the title, and above the help
+		if m.state == NoteReadView {
+			m.refreshReader()
+		}
+
+		historyWidth := availableWidth / 3
+		m.historyList.SetSize(historyWidth, availableHeight-3)
+		m.historyDiff.Width = availableWidth - historyWidth - diffBoxStyle.GetHorizontalFrameSize()
+		m.historyDiff.Height = availableHeight - 3 - diffBoxStyle.GetVerticalFrameSize()
 
 	case notesLoadedMsg:
+		if msg.err != nil {
+			m.status = "Could not load notes: " + msg.err.Error()
+			break
+		}
 		m.notes = msg.notes
+		m.noteBase = make(map[string]model.Note, len(msg.notes))
+		for _, n := range msg.notes {
+			m.noteBase[n.ID] = n
+		}
 		m.updateNoteListItems()
+		m.reindex()
+		if m.state == NoteReadView {
+			m.refreshReader()
+		}
 
 	case todosLoadedMsg:
+		if msg.err != nil {
+			m.status = "Could not load todos: " + msg.err.Error()
+			break
+		}
 		m.todos = msg.todos
+		m.todoBase = make(map[string]model.Todo, len(msg.todos))
+		for _, t := range msg.todos {
+			m.todoBase[t.ID] = t
+		}
+		cmds = append(cmds, m.resetRecurring(time.Now()))
+		m.updateTodoListItems()
+		m.reindex()
+
+	case periodTickMsg:
+		cmds = append(cmds, m.resetRecurring(time.Now()), periodTickCmd())
 		m.updateTodoListItems()
 
 	case noteSavedMsg:
+		if msg.clashed && msg.err == nil {
+			m.status = "Another noteme instance changed this note too: yours is saved, theirs is in its history (H)"
+		} else if status := saveStatus(msg.merged, msg.err); status != "" {
+			m.status = status
+		}
 		return m, m.loadNotesCmd
 
 	case todosSavedMsg:
+		if status := saveStatus(msg.merged, msg.err); status != "" {
+			m.status = status
+		}
 		return m, m.loadTodosCmd
         
+	case revisionsLoadedMsg:
+		if msg.err != nil {
+			m.status = "Could not load history: " + msg.err.Error()
+			break
+		}
+		m.state = HistoryView
+		m.setRevisions(msg.revisions)
+
+	case projectsLoadedMsg:
+		if msg.err != nil {
+			m.status = "Could not load projects: " + msg.err.Error()
+			break
+		}
+		m.projects = msg.projects
+		m.updateTodoListItems()
+		m.reindex()
+
+	case savedSearchesLoadedMsg:
+		if msg.err != nil {
+			m.status = "Could not load saved searches: " + msg.err.Error()
+			break
+		}
+		m.setSavedSearches(msg.searches)
+
+	case savedSearchSavedMsg:
+		if msg.err != nil {
+			m.status = "Save failed: " + msg.err.Error()
+		}
+		return m, m.loadSavedSearchesCmd
+
+	case projectSavedMsg:
+		if msg.err != nil {
+			m.status = "Save failed: " + msg.err.Error()
+		}
+		return m, m.loadProjectsCmd
+
+	case trashLoadedMsg:
+		if msg.err != nil {
+			m.status = "Could not load trash: " + msg.err.Error()
+			break
+		}
+		m.trash = msg.items
+		m.updateTrashListItems()
+
+	case trashRestoredMsg:
+		if msg.err != nil {
+			m.status = "Restore failed: " + msg.err.Error()
+		} else {
+			m.status = "Restored " + msg.item.Kind + " \"" + msg.item.Label() + "\""
+		}
+		return m, tea.Batch(m.loadNotesCmd, m.loadTodosCmd, m.loadTrashCmd)
+
+	case storeChangedMsg:
+		cmds = append(cmds, m.watchCmd())
+		if msg.changed {
+			cmds = append(cmds, m.loadNotesCmd)
+		}
+
     case itemDeletedMsg:
+        if msg.err != nil {
+            m.status = "Delete failed: " + msg.err.Error()
+        }
         // Reload everything to be safe
-        if m.itemToDeleteType == "note" {
-            m.state = NoteListView
-            return m, m.loadNotesCmd
-        } else {
-            m.state = TodoListView
-            return m, m.loadTodosCmd
+        m.state = m.deleteOrigin()
+        switch m.itemToDeleteType {
+        case "note":
+            return m, tea.Batch(m.loadNotesCmd, m.loadTrashCmd)
+        case "todo":
+            return m, tea.Batch(m.loadTodosCmd, m.loadTrashCmd)
+        case itemTypeProject:
+            return m, tea.Batch(m.loadProjectsCmd, m.loadTodosCmd)
+        case itemTypeSearch:
+            return m, m.loadSavedSearchesCmd
+        default:
+            return m, m.loadTrashCmd
         }
 	}
 
@@ -310,13 +665,44 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case TodoListView:
 		m.todoList, cmd = m.todoList.Update(msg)
 		cmds = append(cmds, cmd)
+	case TrashView:
+		m.trashList, cmd = m.trashList.Update(msg)
+		cmds = append(cmds, cmd)
+	case TagView:
+		m.tagList, cmd = m.tagList.Update(msg)
+		cmds = append(cmds, cmd)
+	case ProjectView:
+		m.projectList, cmd = m.projectList.Update(msg)
+		cmds = append(cmds, cmd)
+	case ProjectAddView:
+		m.projectInput, cmd = m.projectInput.Update(msg)
+		cmds = append(cmds, cmd)
+	case MoveNoteView:
+		m.folderPicker, cmd = m.folderPicker.Update(msg)
+		cmds = append(cmds, cmd)
+	case FolderRenameView, MoveNewFolderView:
+		m.folderInput, cmd = m.folderInput.Update(msg)
+		cmds = append(cmds, cmd)
+	case SearchView:
+		m.searchInput, cmd = m.searchInput.Update(msg)
+		cmds = append(cmds, cmd)
+	case SaveSearchView:
+		m.saveSearchInput, cmd = m.saveSearchInput.Update(msg)
+		cmds = append(cmds, cmd)
+	case SavedSearchView:
+		m.savedList, cmd = m.savedList.Update(msg)
+		cmds = append(cmds, cmd)
 	case NoteEditView:
 		m.noteTitleInput, cmd = m.noteTitleInput.Update(msg)
 		cmds = append(cmds, cmd)
 		m.noteFolderInput, cmd = m.noteFolderInput.Update(msg)
 		cmds = append(cmds, cmd)
+		text := m.noteContentInput.Value()
 		m.noteContentInput, cmd = m.noteContentInput.Update(msg)
 		cmds = append(cmds, cmd)
+		if m.noteContentInput.Value() != text {
+			m.refreshPreview()
+		}
 	case TodoAddView:
 		m.todoInput, cmd = m.todoInput.Update(msg)
 		cmds = append(cmds, cmd)
@@ -332,45 +718,112 @@ func (m MainModel) View() string {
 	switch m.state {
 	case DashboardView:
         content = m.renderDashboard()
-        helpKeys = []key.Binding{m.keys.Tab, m.keys.NewNote, m.keys.NewTodo, m.keys.Quit}
+        helpKeys = []key.Binding{m.keys.Tab, m.keys.NewNote, m.keys.NewTodo, m.keys.Tags, m.keys.Quit}
 
 	case NoteListView:
-		content = m.noteList.View()
-        helpKeys = []key.Binding{m.keys.Tab, m.keys.New, m.keys.Enter, m.keys.Delete, m.keys.Up, m.keys.Down, m.keys.Quit}
+		content = lipgloss.JoinHorizontal(lipgloss.Top, m.renderFolders(m.noteList.Height()), m.noteList.View())
+        helpKeys = []key.Binding{m.keys.Tab, m.keys.New, m.keys.Enter, m.keys.Read, m.keys.Delete, m.keys.History, m.keys.Tags, m.keys.Folders, m.keys.Move, m.keys.Search, m.keys.Filter, m.keys.Up, m.keys.Down, m.keys.Quit}
+        if m.folderFocus {
+            helpKeys = m.folderHelp()
+        }
 
 	case TodoListView:
 		content = m.todoList.View()
-        helpKeys = []key.Binding{m.keys.Tab, m.keys.New, m.keys.Toggle, m.keys.Delete, m.keys.Up, m.keys.Down, m.keys.Quit}
+        helpKeys = []key.Binding{m.keys.Tab, m.keys.New, m.keys.Toggle, m.keys.Edit, m.keys.AddSub, m.keys.Indent, m.keys.MoveUp, m.keys.Fold, m.keys.Raise, m.keys.Lower, m.keys.Sort, m.keys.Delete, m.keys.Tags, m.keys.Saved, m.keys.Search, m.keys.Filter, m.keys.Up, m.keys.Down, m.keys.Quit}
 
 	case NoteEditView:
-        content = lipgloss.JoinVertical(lipgloss.Left,
-				titleStyle.Render("Edit Note"),
-				"Title:",
-				m.noteTitleInput.View(),
-				"Folder:",
-				m.noteFolderInput.View(),
-				"Content:",
-				m.noteContentInput.View(),
-		)
+        content = m.renderEditor()
         helpKeys = []key.Binding{m.keys.Tab, m.keys.Save, m.keys.Back}
 
 	case TodoAddView:
+		todoFormTitle := "New Todo"
+		if m.editingTodoID != "" {
+			todoFormTitle = "Edit Todo"
+		} else if m.newTodoParent.ID != "" {
+			todoFormTitle = "New Subtask of \"" + m.newTodoParent.Content + "\""
+		}
 		content = lipgloss.JoinVertical(lipgloss.Left,
-				titleStyle.Render("New Todo"),
-				"Description (append /daily, /weekly, etc.):",
+				titleStyle.Render(todoFormTitle),
+				"Description (e.g. pay rent every month on the 1st !high #home due fri 9am):",
 				m.todoInput.View(),
+				"",
+				todoPreview(m.todoInput.Value(), time.Now()),
 		)
         helpKeys = []key.Binding{m.keys.Enter, m.keys.Back}
     
+	case HistoryView:
+		content = m.renderHistory()
+		helpKeys = []key.Binding{m.keys.Up, m.keys.Down, m.keys.Restore, m.keys.Back}
+
+	case TrashView:
+		content = m.trashList.View()
+		helpKeys = m.trashHelp()
+
+	case TagView:
+		content = m.tagList.View()
+		helpKeys = m.tagHelp()
+
+	case ProjectView:
+		content = m.projectList.View()
+		helpKeys = m.projectHelp()
+
+	case ProjectAddView:
+		content = lipgloss.JoinVertical(lipgloss.Left,
+				titleStyle.Render("New Project"),
+				"Name:",
+				m.projectInput.View(),
+		)
+		helpKeys = []key.Binding{m.keys.Enter, m.keys.Back}
+
+	case MoveNoteView:
+		content = m.folderPicker.View()
+		newFolder := m.keys.New
+		newFolder.SetHelp("n", "new folder")
+		helpKeys = []key.Binding{m.keys.Enter, newFolder, m.keys.Up, m.keys.Down, m.keys.Back}
+
+	case MoveNewFolderView:
+		content = lipgloss.JoinVertical(lipgloss.Left,
+				titleStyle.Render("Move \""+m.movingNote.Title+"\" to a new folder"),
+				"Folder (nest with /):",
+				m.folderInput.View(),
+		)
+		helpKeys = []key.Binding{m.keys.Enter, m.keys.Back}
+
+	case FolderRenameView:
+		content = lipgloss.JoinVertical(lipgloss.Left,
+				titleStyle.Render("Rename Folder "+m.renamingFolder),
+				"Notes in it and its subfolders move along. New name:",
+				m.folderInput.View(),
+		)
+		helpKeys = []key.Binding{m.keys.Enter, m.keys.Back}
+
+	case SearchView:
+		content = m.renderSearch()
+		helpKeys = m.searchHelp()
+
+	case SaveSearchView:
+		content = lipgloss.JoinVertical(lipgloss.Left,
+				titleStyle.Render("Save Search"),
+				"Query: "+strings.TrimSpace(m.searchInput.Value()),
+				"Name (an existing name gets the new query):",
+				m.saveSearchInput.View(),
+		)
+		helpKeys = []key.Binding{m.keys.Enter, m.keys.Back}
+
+	case SavedSearchView:
+		content = m.savedList.View()
+		helpKeys = m.savedSearchHelp()
+
+	case NoteReadView:
+		content = m.renderReader()
+		helpKeys = m.readerHelp()
+
     case DeleteConfirmView:
         content = lipgloss.NewStyle().
             Border(lipgloss.RoundedBorder()).
             BorderForeground(lipgloss.Color("196")). // Red
             Padding(1, 2).
-            Render(fmt.Sprintf(
-                "Are you sure you want to delete this %s?\n\n(y/Enter) Yes    (n/Esc) No",
-                m.itemToDeleteType,
-            ))
+            Render(m.deletePrompt() + "\n\n(y/Enter) Yes    (n/Esc) No")
         // Center it roughly (simple way)
         content = lipgloss.Place(m.width, m.height-5, lipgloss.Center, lipgloss.Center, content)
         helpKeys = []key.Binding{m.keys.Enter, m.keys.Back}
@@ -378,6 +831,9 @@ func (m MainModel) View() string {
     
     // Combine content and help
     helpView := m.help.ShortHelpView(helpKeys)
+	if m.status != "" {
+		helpView = lipgloss.JoinVertical(lipgloss.Left, statusStyle.Render(m.status), helpView)
+	}
     return appStyle.Render(lipgloss.JoinVertical(lipgloss.Left, content, "\n", helpView))
 }
 
@@ -416,14 +872,43 @@ func (m MainModel) renderDashboard() string {
         statLabel.Render("Done:"), statValue.Render(fmt.Sprintf("%d", doneCount)),
     )
     
+    overview := []string{
+        lipgloss.NewStyle().Bold(true).Foreground(primaryColor).Render("Status Overview"),
+        "\n",
+        stats,
+    }
+    if projects := m.renderProjectProgress(5); projects != "" {
+        overview = append(overview, "", projects)
+    }
     statusSection := cardStyle.Width(m.width - 6).Render(
+        lipgloss.JoinVertical(lipgloss.Center, overview...),
+    )
+    
+    // Due today / overdue
+    dueToday, overdue := 0, 0
+    now := time.Now()
+    for _, t := range m.todos {
+        if t.Overdue(now) {
+            overdue++
+        } else if t.DueToday(now) {
+            dueToday++
+        }
+    }
+    overdueValue := statValue
+    if overdue > 0 {
+        overdueValue = overdueStyle
+    }
+    dueSection := cardStyle.Width(m.width - 6).Render(
         lipgloss.JoinVertical(lipgloss.Center,
-            lipgloss.NewStyle().Bold(true).Foreground(primaryColor).Render("Status Overview"),
+            lipgloss.NewStyle().Bold(true).Foreground(primaryColor).Render("Due"),
             "\n",
-            stats,
+            fmt.Sprintf("%s %s    %s %s",
+                statLabel.Render("Today:"), statValue.Render(fmt.Sprintf("%d", dueToday)),
+                statLabel.Render("Overdue:"), overdueValue.Render(fmt.Sprintf("%d", overdue)),
+            ),
         ),
     )
-    
+
     // Navigation Hint
     navHint := lipgloss.NewStyle().
         Foreground(textColor).
@@ -435,6 +920,7 @@ func (m MainModel) renderDashboard() string {
     return lipgloss.JoinVertical(lipgloss.Left,
         header,
         statusSection,
+        dueSection,
         lipgloss.PlaceHorizontal(m.width - 6, lipgloss.Center, navHint),
     )
 }
@@ -453,122 +939,428 @@ func (m MainModel) startNewNote() (tea.Model, tea.Cmd) {
     m.currentNoteID = ""
     m.noteTitleInput.SetValue("")
     m.noteFolderInput.SetValue("general")
+    if m.folderFilter != "" {
+        m.noteFolderInput.SetValue(m.folderFilter)
+    }
+    m.noteFolderInput.SetSuggestions(model.Folders(m.notes))
     m.noteContentInput.SetValue("")
     m.noteTitleInput.Focus()
+    m.refreshPreview()
     return m, nil
 }
 
 func (m MainModel) startNewTodo() (tea.Model, tea.Cmd) {
     m.state = TodoAddView
+    m.editingTodoID = ""
+    m.newTodoParent = model.Todo{}
     m.todoInput.SetValue("")
     m.todoInput.Focus()
     return m, nil
 }
 
+// startEditTodo opens the todo form pre-filled with t, written the way the
+// quick-add parser reads it back.
+func (m MainModel) startEditTodo(t model.Todo) (tea.Model, tea.Cmd) {
+    m.state = TodoAddView
+    m.editingTodoID = t.ID
+    m.todoInput.SetValue(todoInputText(t, m.projectName(t.ProjectID)))
+    m.todoInput.CursorEnd()
+    m.todoInput.Focus()
+    return m, nil
+}
+
+// updateTodo applies the edited fields to the todo being edited, keeping
+// what the form does not show: its ID, creation time and completions.
+func (m *MainModel) updateTodo(edited model.Todo) tea.Cmd {
+    for i, t := range m.todos {
+        if t.ID != m.editingTodoID {
+            continue
+        }
+        t.Content = edited.Content
+        t.Frequency = edited.Frequency
+        t.Recurrence = edited.Recurrence
+        t.Due = edited.Due
+        t.Start = edited.Start
+        t.ProjectID = edited.ProjectID
+        t.Priority = edited.Priority
+        t.Tags = edited.Tags
+        m.todos[i] = t
+        m.editingTodoID = ""
+        m.updateTodoListItems()
+        return m.saveTodoCmd(t)
+    }
+    m.editingTodoID = ""
+    m.status = "That todo no longer exists"
+    return m.loadTodosCmd
+}
+
 
 // Helpers
 
+// listFiltering reports whether the visible list is taking filter input.
+func (m MainModel) listFiltering() bool {
+	switch m.state {
+	case NoteListView:
+		return m.noteList.FilterState() == list.Filtering
+	case TodoListView:
+		return m.todoList.FilterState() == list.Filtering
+	case TrashView:
+		return m.trashList.FilterState() == list.Filtering
+	case TagView:
+		return m.tagList.FilterState() == list.Filtering
+	case ProjectView:
+		return m.projectList.FilterState() == list.Filtering
+	case MoveNoteView:
+		return m.folderPicker.FilterState() == list.Filtering
+	case SavedSearchView:
+		return m.savedList.FilterState() == list.Filtering
+	}
+	return false
+}
+
+// typing reports whether the current view takes text, so that letter keys
+// are input rather than commands.
+func (m MainModel) typing() bool {
+	switch m.state {
+	case NoteEditView, TodoAddView, ProjectAddView, MoveNewFolderView, FolderRenameView, SearchView, SaveSearchView:
+		return true
+	}
+	return false
+}
+
+// selectedTodoIndex is the position in m.todos of the todo selected in the
+// list, or -1.
+func (m MainModel) selectedTodoIndex() int {
+	item, ok := m.todoList.SelectedItem().(todoItem)
+	if !ok {
+		return -1
+	}
+	for i, t := range m.todos {
+		if t.ID == item.todo.ID {
+			return i
+		}
+	}
+	return -1
+}
+
+// selectTodo moves the todo list's cursor to the todo with the given ID.
+func (m *MainModel) selectTodo(id string) {
+	for i, item := range m.todoList.VisibleItems() {
+		if t, ok := item.(todoItem); ok && t.todo.ID == id {
+			m.todoList.Select(i)
+			return
+		}
+	}
+}
+
 func (m *MainModel) updateNoteListItems() {
-	items := make([]list.Item, len(m.notes))
-	for i, n := range m.notes {
-		items[i] = noteItem{n}
+	saved := m.savedQuery(m.noteSearch)
+	items := make([]list.Item, 0, len(m.notes))
+	for _, n := range m.notes {
+		if model.InFolder(n.Folder, m.folderFilter) && model.MatchTags(n.TagSet(), m.tagFilter, m.tagMatchAll) && saved.Match(query.Note(n)) {
+			items = append(items, noteItem{n})
+		}
 	}
 	m.noteList.SetItems(items)
+	title := "Notes"
+	if m.folderFilter != "" {
+		title += " in " + m.folderFilter
+	}
+	title += m.savedSearchTitle(m.noteSearch)
+	m.noteList.Title = m.filterTitle(title)
 }
 
 func (m *MainModel) updateTodoListItems() {
-	items := make([]list.Item, len(m.todos))
-	for i, t := range m.todos {
-		items[i] = todoItem{t}
+	m.sortTodos(time.Now())
+	saved := m.savedQuery(m.todoSearch)
+	var visible []model.Todo
+	for _, t := range m.todos {
+		if m.projectFilter != "" && t.ProjectID != m.projectFilter {
+			continue
+		}
+		if !saved.Match(query.Todo(t, m.projectName(t.ProjectID))) {
+			continue
+		}
+		if model.MatchTags(t.Tags, m.tagFilter, m.tagMatchAll) {
+			visible = append(visible, t)
+		}
+	}
+	nodes := model.Tree(visible, m.collapsed)
+	items := make([]list.Item, len(nodes))
+	for i, n := range nodes {
+		items[i] = todoItem{
+			todo:      n.Todo,
+			project:   m.projectName(n.ProjectID),
+			depth:     n.Depth,
+			subtasks:  n.Subtasks,
+			finished:  n.Finished,
+			collapsed: m.collapsed[n.ID],
+		}
 	}
 	m.todoList.SetItems(items)
+	m.todoList.Title = m.todoListTitle()
+	m.updateProjectListItems()
 }
 
 // Commands & Messages
 
-type notesLoadedMsg struct{ notes []model.Note }
-type todosLoadedMsg struct{ todos []model.Todo }
-type noteSavedMsg struct{}
-type todosSavedMsg struct{}
-type itemDeletedMsg struct{}
+type notesLoadedMsg struct {
+	notes []model.Note
+	err   error
+}
+type todosLoadedMsg struct {
+	todos []model.Todo
+	err   error
+}
+type noteSavedMsg struct {
+	err    error
+	merged bool // another writer changed other parts of the note; theirs were kept
+	// another writer changed the same parts: ours was saved, theirs went to
+	// the history
+	clashed bool
+}
+type todosSavedMsg struct {
+	err    error
+	merged bool
+}
+type itemDeletedMsg struct{ err error }
+type storeChangedMsg struct{ changed bool }
+
+// How often backends that can be edited from outside are polled.
+const watchInterval = 2 * time.Second
 
 func (m MainModel) loadNotesCmd() tea.Msg {
-	notes, _ := m.store.LoadNotes()
-	return notesLoadedMsg{notes}
+	notes, err := m.store.LoadNotes()
+	return notesLoadedMsg{notes, err}
 }
 
 func (m MainModel) loadTodosCmd() tea.Msg {
-	todos, _ := m.store.LoadTodos()
-	return todosLoadedMsg{todos}
+	todos, err := m.store.LoadTodos()
+	return todosLoadedMsg{todos, err}
 }
 
 func (m MainModel) saveNoteCmd() tea.Cmd {
-	return func() tea.Msg {
-		// Construct note
-		note := model.Note{
-			ID:        m.currentNoteID,
-			Title:     m.noteTitleInput.Value(),
-			Content:   m.noteContentInput.Value(),
-			CreatedAt: time.Now(),
-			Folder:    m.noteFolderInput.Value(),
+	// Construct note
+	note := model.Note{
+		ID:        m.currentNoteID,
+		Title:     m.noteTitleInput.Value(),
+		Content:   m.noteContentInput.Value(),
+		CreatedAt: time.Now(),
+		Folder:    model.CleanFolder(m.noteFolderInput.Value()),
+	}
+	if note.ID == "" {
+		note.ID = uuid.New().String()
+	}
+	for _, n := range m.notes {
+		if n.ID == note.ID {
+			note.CreatedAt = n.CreatedAt // Keep original creation time
+			break
 		}
+	}
 
-		if note.ID == "" {
-			note.ID = uuid.New().String()
-			m.notes = append([]model.Note{note}, m.notes...)
-		} else {
-			// Update existing
-			for i, n := range m.notes {
-				if n.ID == note.ID {
-					note.CreatedAt = n.CreatedAt // Keep original creation time
-					m.notes[i] = note
-					break
-				}
-			}
+	return m.upsertNoteCmd(note)
+}
+
+// upsertNoteCmd saves note and records the saved version in its history,
+// after the version it replaces if the history has nothing yet.
+func (m MainModel) upsertNoteCmd(note model.Note) tea.Cmd {
+	var prev model.Note
+	for _, n := range m.notes {
+		if n.ID == note.ID {
+			prev = n
+			break
 		}
+	}
+	note.UpdateTags(prev)
+	base := m.noteBase[note.ID]
+	m.noteBase[note.ID] = note
+	return func() tea.Msg {
+		return m.saveNote(base, note)
+	}
+}
 
-		m.store.SaveNotes(m.notes)
-		return noteSavedMsg{}
+// saveNote saves note, edited from base, and records it in the history.
+// With a backend that checks, changes another noteme instance made to the
+// note meanwhile are merged in; if they clash with ours, ours is saved and
+// theirs is kept in the history. Run it from a command.
+func (m MainModel) saveNote(base, note model.Note) noteSavedMsg {
+	if err := m.recordOriginal(note.ID); err != nil {
+		return noteSavedMsg{err: err}
+	}
+	var msg noteSavedMsg
+	v, ok := m.store.(storage.Versioned)
+	if ok {
+		msg.merged, msg.err = v.UpsertNoteFrom(base, note)
+	} else {
+		msg.err = m.store.UpsertNote(note)
+	}
+	if errors.Is(msg.err, storage.ErrConflict) {
+		msg.merged, msg.clashed = false, true
+		theirs, err := m.store.GetNote(note.ID)
+		if err == nil {
+			err = m.recordRevision(theirs)
+		} else if errors.Is(err, storage.ErrNotFound) {
+			err = nil // deleted meanwhile: ours brings it back
+		}
+		if err != nil {
+			return noteSavedMsg{err: err}
+		}
+		msg.err = m.store.UpsertNote(note)
+	}
+	if msg.err != nil {
+		return msg
 	}
+	if msg.merged {
+		if note, msg.err = m.store.GetNote(note.ID); msg.err != nil {
+			return msg
+		}
+	}
+	msg.err = m.recordRevision(note)
+	return msg
 }
 
-func (m MainModel) saveTodosCmd() tea.Cmd {
+// saveTodoCmd saves todo, merged with any change another noteme instance
+// made to it since it was loaded if the backend checks for that.
+func (m MainModel) saveTodoCmd(todo model.Todo) tea.Cmd {
+	base := m.todoBase[todo.ID]
+	m.todoBase[todo.ID] = todo
 	return func() tea.Msg {
-		m.store.SaveTodos(m.todos)
-		return todosSavedMsg{}
+		if v, ok := m.store.(storage.Versioned); ok {
+			merged, err := v.UpsertTodoFrom(base, todo)
+			return todosSavedMsg{err, merged}
+		}
+		return todosSavedMsg{err: m.store.UpsertTodo(todo)}
 	}
 }
 
+// saveStatus describes how a save went, or is "" if it simply worked.
+func saveStatus(merged bool, err error) string {
+	switch {
+	case errors.Is(err, storage.ErrConflict):
+		return "Not saved: another noteme instance changed it too"
+	case err != nil:
+		return "Save failed: " + err.Error()
+	case merged:
+		return "Merged with changes made by another noteme instance"
+	}
+	return ""
+}
+
+// watchCmd polls the backend for outside changes, if it supports that.
+func (m MainModel) watchCmd() tea.Cmd {
+	notifier, ok := m.store.(storage.ChangeNotifier)
+	if !ok {
+		return nil
+	}
+	return tea.Tick(watchInterval, func(time.Time) tea.Msg {
+		changed, _ := notifier.Changed()
+		return storeChangedMsg{changed}
+	})
+}
+
 func (m MainModel) deleteItemCmd() tea.Cmd {
     return func() tea.Msg {
-        if m.itemToDeleteType == "note" {
-            m.store.DeleteNote(m.itemToDeleteID)
-        } else {
-            m.store.DeleteTodo(m.itemToDeleteID)
+        switch m.itemToDeleteType {
+        case "note":
+            return itemDeletedMsg{m.store.DeleteNote(m.itemToDeleteID)}
+        case "todo":
+            return itemDeletedMsg{m.store.DeleteTodo(m.itemToDeleteID)}
+        case itemTypeProject:
+            return m.deleteProjectCmd(m.itemToDeleteID)
+        case itemTypeSearch:
+            return m.deleteSavedSearchCmd(m.itemToDeleteID)
+        default:
+            return itemDeletedMsg{m.store.(storage.Trash).PurgeItem(m.itemToDeleteID)}
         }
-        return itemDeletedMsg{}
     }
 }
 
+// deleteOrigin is the view a delete confirmation returns to.
+func (m MainModel) deleteOrigin() sessionState {
+    switch m.itemToDeleteType {
+    case "note":
+        return NoteListView
+    case "todo":
+        return TodoListView
+    case itemTypeProject:
+        return ProjectView
+    case itemTypeSearch:
+        return m.savedDeleteOrigin
+    default:
+        return TrashView
+    }
+}
+
+func (m MainModel) deletePrompt() string {
+    switch {
+    case m.itemToDeleteType == itemTypeTrashed:
+        return "Delete this item forever? It cannot be restored."
+    case m.itemToDeleteType == itemTypeProject:
+        return "Delete this project? Its todos are kept."
+    case m.itemToDeleteType == itemTypeSearch:
+        return "Delete this saved search? Notes and todos are kept."
+    case m.hasTrash():
+        return fmt.Sprintf("Move this %s to the trash?", m.itemToDeleteType)
+    }
+    return fmt.Sprintf("Are you sure you want to delete this %s?", m.itemToDeleteType)
+}
+
 // List Items Adapters
 type noteItem struct{ note model.Note }
 
 func (n noteItem) FilterValue() string { return n.note.Title }
 func (n noteItem) Title() string       { return n.note.Title }
 func (n noteItem) Description() string {
-	return fmt.Sprintf("[%s] %s", n.note.Folder, n.note.CreatedAt.Format("2006-01-02"))
+	desc := fmt.Sprintf("[%s] %s", n.note.Folder, n.note.CreatedAt.Format("2006-01-02"))
+	if tags := tagsLabel(n.note.TagSet()); tags != "" {
+		desc += " " + tags
+	}
+	return desc
 }
 
-type todoItem struct{ todo model.Todo }
+type todoItem struct {
+	todo    model.Todo
+	project string // name of the todo's project
+
+	// Place in the subtask tree
+	depth              int
+	subtasks, finished int
+	collapsed          bool
+}
 
 func (t todoItem) FilterValue() string { return t.todo.Content }
 func (t todoItem) Title() string {
 	prefix := "[ ] "
 	if t.todo.Done {
 		prefix = "[x] "
+	} else if t.todo.Overdue(time.Now()) {
+		prefix = "[!] "
+	}
+	title := t.treePrefix() + prefix + t.todo.Content
+	if progress := t.progressLabel(); progress != "" {
+		title += " " + progress
 	}
-	return prefix + t.todo.Content
+	return title
 }
 func (t todoItem) Description() string {
-	return string(t.todo.Frequency) + " | " + t.todo.CreatedAt.Format("2006-01-02")
+	parts := []string{recurrenceLabel(t.todo), t.todo.CreatedAt.Format("2006-01-02")}
+	if prio := priorityLabel(t.todo.Priority); prio != "" {
+		parts = append(parts, prio)
+	}
+	if t.project != "" {
+		parts = append(parts, "+"+t.project)
+	}
+	if schedule := scheduleLabel(t.todo, time.Now()); schedule != "" {
+		parts = append(parts, schedule)
+	}
+	if due := dueLabel(t.todo, time.Now()); due != "" && t.todo.Due.IsZero() {
+		parts = append(parts, due)
+	}
+	if done := completionsLabel(t.todo); done != "" && t.todo.Recurring() {
+		parts = append(parts, done)
+	}
+	if tags := tagsLabel(t.todo.Tags); tags != "" {
+		parts = append(parts, tags)
+	}
+	return strings.Join(parts, " | ")
 }
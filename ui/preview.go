@@ -0,0 +1,202 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mtix28/noteme/model"
+)
+
+// Markdown preview: notes rendered as Markdown, in colors taken from
+// styles.go. p in the note list opens the selected note to read; on wide
+// terminals the editor shows a live preview beside the text.
+
+// splitMinWidth is the narrowest terminal the editor shows a preview on.
+const splitMinWidth = 120
+
+// markdownStyle is glamour's Dracula theme, which the app's palette comes
+// from, with headings, links and code in the app's own colors.
+func markdownStyle() ansi.StyleConfig {
+	color := func(c lipgloss.Color) *string { s := string(c); return &s }
+	var noMargin uint
+
+	s := styles.DraculaStyleConfig
+	s.Document.Margin = &noMargin
+	s.Document.BlockPrefix, s.Document.BlockSuffix = "", ""
+	s.Document.Color = color(textColor)
+	s.Heading.Color = color(primaryColor)
+	s.Link.Color = color(subtleColor)
+	s.LinkText.Color = color(secondaryColor)
+	s.Code.Color = color(accentColor)
+	s.HorizontalRule.Color = color(subtleColor)
+	s.BlockQuote.Color = color(subtleColor)
+	return s
+}
+
+// renderMarkdown renders text wrapped to width. Text glamour can't render
+// is shown as it is.
+func (m *MainModel) renderMarkdown(text string, width int) string {
+	r, err := m.markdownRenderer(max(width, 1))
+	if err != nil {
+		return text
+	}
+	out, err := r.Render(text)
+	if err != nil {
+		return text
+	}
+	return strings.Trim(out, "\n")
+}
+
+// markdownRenderer returns the renderer for width. Renderers are made once
+// per width and kept until the window is resized.
+func (m *MainModel) markdownRenderer(width int) (*glamour.TermRenderer, error) {
+	if r, ok := m.markdown[width]; ok {
+		return r, nil
+	}
+	r, err := glamour.NewTermRenderer(
+		glamour.WithStyles(markdownStyle()),
+		glamour.WithWordWrap(width),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if m.markdown == nil {
+		m.markdown = map[int]*glamour.TermRenderer{}
+	}
+	m.markdown[width] = r
+	return r, nil
+}
+
+// splitEditor reports whether the editor has room for a preview beside it.
+func (m MainModel) splitEditor() bool {
+	h, _ := appStyle.GetFrameSize()
+	return m.width-h >= splitMinWidth
+}
+
+// resizeEditor lays the editor out for the window: the full width, or the
+// left half with the preview on the right.
+func (m *MainModel) resizeEditor(width, height int) {
+	m.noteContentInput.SetHeight(height - 10)
+	if !m.splitEditor() {
+		m.noteContentInput.SetWidth(width)
+		return
+	}
+	editorWidth := width / 2
+	m.noteContentInput.SetWidth(editorWidth - 1)
+	m.notePreview.Width = width - editorWidth - previewBoxStyle.GetHorizontalFrameSize()
+	m.notePreview.Height = height - 4 - previewBoxStyle.GetVerticalFrameSize() // level with the text area
+	m.refreshPreview()
+}
+
+// refreshPreview renders the note being edited into the preview.
+func (m *MainModel) refreshPreview() {
+	if !m.splitEditor() {
+		return
+	}
+	text := m.noteContentInput.Value()
+	if strings.TrimSpace(text) == "" {
+		m.notePreview.SetContent(emptyStateStyle.Render("The preview shows up here as you type."))
+		return
+	}
+	m.notePreview.SetContent(m.renderMarkdown(text, m.notePreview.Width))
+}
+
+func (m MainModel) renderEditor() string {
+	editor := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("Edit Note"),
+		"Title:",
+		m.noteTitleInput.View(),
+		"Folder:",
+		m.noteFolderInput.View(),
+		"Content:",
+		m.noteContentInput.View(),
+	)
+	if !m.splitEditor() {
+		return editor
+	}
+	preview := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.MarginBottom(0).Render("Preview"),
+		previewBoxStyle.Render(m.notePreview.View()),
+	)
+	return lipgloss.JoinHorizontal(lipgloss.Top, editor, " ", preview)
+}
+
+// openReader shows the selected note rendered.
+func (m MainModel) openReader() (tea.Model, tea.Cmd) {
+	item, ok := m.noteList.SelectedItem().(noteItem)
+	if !ok {
+		return m, nil
+	}
+	m.state = NoteReadView
+	m.readingNote = item.note
+	m.refreshReader()
+	m.noteReader.GotoTop()
+	return m, nil
+}
+
+// refreshReader renders the note being read, as it is now.
+func (m *MainModel) refreshReader() {
+	for _, n := range m.notes {
+		if n.ID == m.readingNote.ID {
+			m.readingNote = n
+		}
+	}
+	if strings.TrimSpace(m.readingNote.Content) == "" {
+		m.noteReader.SetContent(emptyStateStyle.Render("This note is empty."))
+		return
+	}
+	m.noteReader.SetContent(m.renderMarkdown(m.readingNote.Content, m.noteReader.Width))
+}
+
+func (m MainModel) updateReader(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.state = NoteListView
+		return m, nil
+	case key.Matches(msg, m.keys.Edit), key.Matches(msg, m.keys.Enter):
+		return m.editNote(m.readingNote)
+	}
+
+	var cmd tea.Cmd
+	m.noteReader, cmd = m.noteReader.Update(msg)
+	return m, cmd
+}
+
+func (m MainModel) renderReader() string {
+	n := m.readingNote
+	meta := "[" + n.Folder + "] " + n.CreatedAt.Format("2006-01-02")
+	if tags := tagsLabel(n.TagSet()); tags != "" {
+		meta += " " + tags
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.MarginBottom(0).Render(n.Title),
+		statLabel.Render(meta),
+		"",
+		m.noteReader.View(),
+	)
+}
+
+func (m MainModel) readerHelp() []key.Binding {
+	edit := m.keys.Edit
+	edit.SetHelp("e", "edit")
+	scroll := key.NewBinding(key.WithKeys("pgup", "pgdown"), key.WithHelp("pgup/pgdn", "page"))
+	return []key.Binding{m.keys.Up, m.keys.Down, scroll, edit, m.keys.Back}
+}
+
+// editNote opens the note in the editor.
+func (m MainModel) editNote(n model.Note) (tea.Model, tea.Cmd) {
+	m.state = NoteEditView
+	m.currentNoteID = n.ID
+	m.noteTitleInput.SetValue(n.Title)
+	m.noteFolderInput.SetValue(n.Folder)
+	m.noteFolderInput.SetSuggestions(model.Folders(m.notes))
+	m.noteContentInput.SetValue(n.Content)
+	m.noteTitleInput.Focus()
+	m.refreshPreview()
+	return m, nil
+}
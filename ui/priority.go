@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"io"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mtix28/noteme/model"
+)
+
+// todoSort is how the todo list is ordered.
+type todoSort int
+
+const (
+	sortByUrgency  todoSort = iota // model.SortTodos
+	sortByPriority                 // model.SortTodosByPriority
+	sortByHand                     // model.SortTodosByPosition
+)
+
+var priorityColors = map[model.Priority]lipgloss.Color{
+	model.PriorityLow:    priorityLowColor,
+	model.PriorityMedium: priorityMediumColor,
+	model.PriorityHigh:   priorityHighColor,
+	model.PriorityUrgent: priorityUrgentColor,
+}
+
+// sortTodos orders m.todos by the current sort mode.
+func (m *MainModel) sortTodos(now time.Time) {
+	switch m.todoSort {
+	case sortByPriority:
+		model.SortTodosByPriority(m.todos, now)
+	case sortByHand:
+		model.SortTodosByPosition(m.todos)
+	default:
+		model.SortTodos(m.todos, now)
+	}
+}
+
+func (m MainModel) todoListTitle() string {
+	title := "Todos"
+	switch m.todoSort {
+	case sortByPriority:
+		title += " by priority"
+	case sortByHand:
+		title += " in your order"
+	}
+	if name := m.projectName(m.projectFilter); name != "" {
+		title += " · +" + name
+	}
+	title += m.savedSearchTitle(m.todoSearch)
+	return m.filterTitle(title)
+}
+
+// shiftPriority raises or lowers the priority of the selected todo.
+func (m MainModel) shiftPriority(raise bool) (tea.Model, tea.Cmd) {
+	i := m.selectedTodoIndex()
+	if i < 0 {
+		return m, nil
+	}
+	t := m.todos[i]
+	if raise {
+		t.Priority = t.Priority.Raise()
+	} else {
+		t.Priority = t.Priority.Lower()
+	}
+	if t.Priority == m.todos[i].Priority {
+		return m, nil
+	}
+	m.todos[i] = t
+	// The todo may move in the list; keep it selected so the key can be
+	// pressed again.
+	m.updateTodoListItems()
+	m.selectTodo(t.ID)
+	return m, m.saveTodoCmd(t)
+}
+
+// priorityLabel is the priority as shown in a todo's description.
+func priorityLabel(p model.Priority) string {
+	if p == model.PriorityNone {
+		return ""
+	}
+	return "!" + string(p)
+}
+
+// todoDelegate renders todos like the default delegate, with the title in
+// the color of the todo's priority.
+type todoDelegate struct{ list.DefaultDelegate }
+
+func newTodoDelegate() todoDelegate {
+	return todoDelegate{list.NewDefaultDelegate()}
+}
+
+func (d todoDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if t, ok := item.(todoItem); ok && !t.todo.Done {
+		if c, ok := priorityColors[t.todo.Priority]; ok {
+			d.Styles.NormalTitle = d.Styles.NormalTitle.Foreground(c)
+			d.Styles.SelectedTitle = d.Styles.SelectedTitle.Foreground(c)
+		}
+	}
+	d.DefaultDelegate.Render(w, m, index, item)
+}
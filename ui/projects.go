@@ -0,0 +1,238 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/google/uuid"
+	"github.com/mtix28/noteme/model"
+	"github.com/mtix28/noteme/storage"
+)
+
+// Projects view: every project with its open and done todos and a progress
+// bar, for backends that store projects. Enter shows only the project's
+// todos; esc in the todo list goes back.
+
+// itemTypeProject is the itemToDeleteType used when deleting a project.
+const itemTypeProject = "project"
+
+type projectsLoadedMsg struct {
+	projects []model.Project
+	err      error
+}
+
+type projectSavedMsg struct{ err error }
+
+func (m MainModel) loadProjectsCmd() tea.Msg {
+	projects, ok := m.store.(storage.Projects)
+	if !ok {
+		return nil
+	}
+	items, err := projects.LoadProjects()
+	return projectsLoadedMsg{items, err}
+}
+
+func (m MainModel) hasProjects() bool {
+	_, ok := m.store.(storage.Projects)
+	return ok
+}
+
+func (m MainModel) saveProjectCmd(p model.Project) tea.Cmd {
+	store := m.store.(storage.Projects)
+	return func() tea.Msg {
+		return projectSavedMsg{store.UpsertProject(p)}
+	}
+}
+
+func (m MainModel) updateProjects(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Tab):
+		if m.hasTrash() {
+			m.state = TrashView
+		} else {
+			m.state = DashboardView
+		}
+		return m, nil
+	case key.Matches(msg, m.keys.Back):
+		m.state = DashboardView
+		return m, nil
+	case key.Matches(msg, m.keys.New):
+		m.state = ProjectAddView
+		m.projectInput.SetValue("")
+		m.projectInput.Focus()
+		return m, nil
+	case key.Matches(msg, m.keys.Enter):
+		if item, ok := m.projectList.SelectedItem().(projectItem); ok {
+			m.projectFilter = item.project.ID
+			m.state = TodoListView
+			m.updateTodoListItems()
+			m.todoList.Select(0)
+		}
+		return m, nil
+	case key.Matches(msg, m.keys.Delete):
+		if item, ok := m.projectList.SelectedItem().(projectItem); ok {
+			m.itemToDeleteID = item.project.ID
+			m.itemToDeleteType = itemTypeProject
+			m.state = DeleteConfirmView
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.projectList, cmd = m.projectList.Update(msg)
+	return m, cmd
+}
+
+func (m MainModel) updateProjectAdd(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.state = ProjectView
+		return m, nil
+	case key.Matches(msg, m.keys.Enter):
+		name := model.ProjectName(m.projectInput.Value())
+		if name == "" {
+			return m, nil
+		}
+		if _, ok := model.FindProject(m.projects, name); ok {
+			m.status = "There already is a project called " + name
+			return m, nil
+		}
+		m.state = ProjectView
+		_, cmd := m.projectFor(name)
+		m.updateProjectListItems()
+		return m, cmd
+	}
+
+	var cmd tea.Cmd
+	m.projectInput, cmd = m.projectInput.Update(msg)
+	return m, cmd
+}
+
+// projectFor returns the ID of the project called name, creating it if
+// there is none. The command saves a new project.
+func (m *MainModel) projectFor(name string) (string, tea.Cmd) {
+	name = model.ProjectName(name)
+	if name == "" || !m.hasProjects() {
+		return "", nil
+	}
+	if p, ok := model.FindProject(m.projects, name); ok {
+		return p.ID, nil
+	}
+	p := model.Project{ID: uuid.New().String(), Name: name, CreatedAt: time.Now()}
+	m.projects = append(m.projects, p)
+	return p.ID, m.saveProjectCmd(p)
+}
+
+// projectName is the name of the project with the given ID, or "".
+func (m MainModel) projectName(id string) string {
+	for _, p := range m.projects {
+		if p.ID == id {
+			return p.Name
+		}
+	}
+	return ""
+}
+
+// deleteProjectCmd deletes a project and takes its todos out of it.
+func (m MainModel) deleteProjectCmd(id string) tea.Msg {
+	if err := m.store.(storage.Projects).DeleteProject(id); err != nil {
+		return itemDeletedMsg{err}
+	}
+	for _, t := range m.todos {
+		if t.ProjectID == id {
+			t.ProjectID = ""
+			if err := m.store.UpsertTodo(t); err != nil {
+				return itemDeletedMsg{err}
+			}
+		}
+	}
+	return itemDeletedMsg{}
+}
+
+// projectsByProgress returns the projects with their progress, the ones
+// with the most open todos first.
+func (m MainModel) projectsByProgress() []projectItem {
+	progress := model.ProjectProgress(m.todos)
+	items := make([]projectItem, len(m.projects))
+	for i, p := range m.projects {
+		items[i] = projectItem{p, progress[p.ID]}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].progress.Open != items[j].progress.Open {
+			return items[i].progress.Open > items[j].progress.Open
+		}
+		return items[i].project.Name < items[j].project.Name
+	})
+	return items
+}
+
+func (m *MainModel) updateProjectListItems() {
+	projects := m.projectsByProgress()
+	items := make([]list.Item, len(projects))
+	for i, p := range projects {
+		items[i] = p
+	}
+	m.projectList.SetItems(items)
+}
+
+// renderProjectProgress is the per-project part of the dashboard's status
+// overview.
+func (m MainModel) renderProjectProgress(limit int) string {
+	projects := m.projectsByProgress()
+	if len(projects) == 0 {
+		return ""
+	}
+	width := 0
+	for _, p := range projects {
+		width = max(width, lipgloss.Width(p.project.Name))
+	}
+	var rows []string
+	for i, p := range projects {
+		if i == limit {
+			rows = append(rows, statLabel.Render(fmt.Sprintf("… and %d more", len(projects)-limit)))
+			break
+		}
+		filled := filledCells(p.progress.Ratio(), 20)
+		rows = append(rows, fmt.Sprintf("%s  %s%s %s",
+			statLabel.Render(fmt.Sprintf("%-*s", width, p.project.Name)),
+			progressFillStyle.Render(strings.Repeat("█", filled)),
+			progressTrackStyle.Render(strings.Repeat("░", 20-filled)),
+			statValue.Render(fmt.Sprintf("%d/%d", p.progress.Done, p.progress.Total())),
+		))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// progressBar draws ratio as a bar of width cells.
+func progressBar(ratio float64, width int) string {
+	filled := filledCells(ratio, width)
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+func filledCells(ratio float64, width int) int {
+	return min(int(ratio*float64(width)+0.5), width)
+}
+
+func (m MainModel) projectHelp() []key.Binding {
+	open := m.keys.Enter
+	open.SetHelp("enter", "show todos")
+	return []key.Binding{m.keys.Tab, m.keys.New, open, m.keys.Delete, m.keys.Up, m.keys.Down, m.keys.Quit}
+}
+
+type projectItem struct {
+	project  model.Project
+	progress model.Progress
+}
+
+func (p projectItem) FilterValue() string { return p.project.Name }
+func (p projectItem) Title() string       { return "+" + p.project.Name }
+func (p projectItem) Description() string {
+	return fmt.Sprintf("%s %3.0f%% | %d open · %d done",
+		progressBar(p.progress.Ratio(), 20), p.progress.Ratio()*100, p.progress.Open, p.progress.Done)
+}
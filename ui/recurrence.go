@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mtix28/noteme/model"
+)
+
+// Recurring todos are unchecked again when a new day, week or month starts.
+// This happens whenever todos are loaded and at every local midnight while
+// the app is running.
+
+type periodTickMsg struct{}
+
+// periodTickCmd fires at the next local midnight, which is where every
+// period boundary falls.
+func periodTickCmd() tea.Cmd {
+	next := model.Daily.NextPeriod(time.Now())
+	return tea.Tick(time.Until(next), func(time.Time) tea.Msg {
+		return periodTickMsg{}
+	})
+}
+
+// resetRecurring unchecks the recurring todos whose period has passed and
+// returns a command saving them.
+func (m *MainModel) resetRecurring(now time.Time) tea.Cmd {
+	var cmds []tea.Cmd
+	for i := range m.todos {
+		if m.todos[i].Reset(now) {
+			cmds = append(cmds, m.saveTodoCmd(m.todos[i]))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// dueLabel describes when a recurring todo is next due.
+func dueLabel(t model.Todo, now time.Time) string {
+	if !t.Recurring() {
+		return ""
+	}
+	due := t.NextDue(now)
+	today := model.Daily.PeriodStart(now)
+	tomorrow := model.Daily.NextPeriod(now)
+	switch {
+	case due.IsZero():
+		return "finished"
+	case t.DueNow(now) && due.Before(today):
+		return "due since " + due.Format("Mon Jan 02")
+	case t.DueNow(now):
+		return "due today"
+	case due.Before(model.Daily.NextPeriod(tomorrow)):
+		return "next due tomorrow"
+	}
+	return "next due " + due.Format("Mon Jan 02")
+}
+
+// recurrenceLabel is the todo's RRULE, or its frequency if it has none.
+func recurrenceLabel(t model.Todo) string {
+	if t.Recurrence != "" {
+		return t.Recurrence
+	}
+	return string(t.Frequency)
+}
+
+func completionsLabel(t model.Todo) string {
+	if len(t.Completions) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("done %d×", len(t.Completions))
+}
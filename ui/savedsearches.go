@@ -0,0 +1,265 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/uuid"
+	"github.com/mtix28/noteme/model"
+	"github.com/mtix28/noteme/query"
+	"github.com/mtix28/noteme/storage"
+)
+
+// Saved searches: a query from the search view kept under a name, for
+// backends that store them. In the notes view they are smart folders in
+// the sidebar; in the todo view v picks one as a filter. Both show how
+// many notes or todos match, counted again whenever the data changes.
+
+// itemTypeSearch is the itemToDeleteType used when deleting a saved search.
+const itemTypeSearch = "saved search"
+
+type savedSearchesLoadedMsg struct {
+	searches []model.SavedSearch
+	err      error
+}
+
+type savedSearchSavedMsg struct{ err error }
+
+// savedCount is how many notes and todos a saved search matches.
+type savedCount struct{ notes, todos int }
+
+func (m MainModel) loadSavedSearchesCmd() tea.Msg {
+	searches, ok := m.store.(storage.SavedSearches)
+	if !ok {
+		return nil
+	}
+	items, err := searches.LoadSavedSearches()
+	return savedSearchesLoadedMsg{items, err}
+}
+
+func (m MainModel) hasSavedSearches() bool {
+	_, ok := m.store.(storage.SavedSearches)
+	return ok
+}
+
+func (m MainModel) saveSavedSearchCmd(s model.SavedSearch) tea.Cmd {
+	store := m.store.(storage.SavedSearches)
+	return func() tea.Msg {
+		return savedSearchSavedMsg{store.UpsertSavedSearch(s)}
+	}
+}
+
+// setSavedSearches keeps the loaded saved searches in name order, dropping
+// filters whose search is gone.
+func (m *MainModel) setSavedSearches(searches []model.SavedSearch) {
+	sort.SliceStable(searches, func(i, j int) bool {
+		return strings.ToLower(searches[i].Name) < strings.ToLower(searches[j].Name)
+	})
+	m.savedSearches = searches
+	if _, ok := m.savedSearch(m.noteSearch); !ok {
+		m.noteSearch = ""
+	}
+	if _, ok := m.savedSearch(m.todoSearch); !ok {
+		m.todoSearch = ""
+	}
+	m.countSavedSearches()
+	m.updateNoteListItems()
+	m.updateTodoListItems()
+}
+
+func (m MainModel) savedSearch(id string) (model.SavedSearch, bool) {
+	for _, s := range m.savedSearches {
+		if s.ID == id {
+			return s, true
+		}
+	}
+	return model.SavedSearch{}, false
+}
+
+// savedQuery parses the saved search with the given ID. A search that is
+// gone, or no longer parses, is an empty query and matches everything.
+func (m MainModel) savedQuery(id string) *query.Query {
+	s, ok := m.savedSearch(id)
+	if !ok {
+		return &query.Query{}
+	}
+	q, err := query.Parse(s.Query, time.Now())
+	if err != nil {
+		return &query.Query{}
+	}
+	return q
+}
+
+// countSavedSearches counts the matches of every saved search.
+func (m *MainModel) countSavedSearches() {
+	m.savedCounts = make(map[string]savedCount, len(m.savedSearches))
+	for _, s := range m.savedSearches {
+		q := m.savedQuery(s.ID)
+		var c savedCount
+		for _, n := range m.notes {
+			if q.Match(query.Note(n)) {
+				c.notes++
+			}
+		}
+		for _, t := range m.todos {
+			if q.Match(query.Todo(t, m.projectName(t.ProjectID))) {
+				c.todos++
+			}
+		}
+		m.savedCounts[s.ID] = c
+	}
+	m.updateSavedListItems()
+}
+
+// startSaveSearch asks for a name to save the query in the search box
+// under.
+func (m MainModel) startSaveSearch() (tea.Model, tea.Cmd) {
+	if !m.hasSavedSearches() {
+		m.status = "This storage backend can't save searches"
+		return m, nil
+	}
+	q, err := query.Parse(m.searchInput.Value(), time.Now())
+	if err != nil || q.Empty() {
+		m.status = "Type a query to save first"
+		return m, nil
+	}
+	m.state = SaveSearchView
+	m.saveSearchInput.SetValue("")
+	m.saveSearchInput.Focus()
+	return m, nil
+}
+
+func (m MainModel) updateSaveSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.state = SearchView
+		return m, nil
+	case key.Matches(msg, m.keys.Enter):
+		name := strings.TrimSpace(m.saveSearchInput.Value())
+		if name == "" {
+			return m, nil
+		}
+		s, ok := model.FindSavedSearch(m.savedSearches, name)
+		if !ok {
+			s = model.SavedSearch{ID: uuid.New().String(), Name: name, CreatedAt: time.Now()}
+			m.savedSearches = append(m.savedSearches, s)
+		}
+		s.Query = strings.TrimSpace(m.searchInput.Value())
+		for i := range m.savedSearches {
+			if m.savedSearches[i].ID == s.ID {
+				m.savedSearches[i] = s
+			}
+		}
+		m.countSavedSearches()
+		m.state = SearchView
+		m.status = fmt.Sprintf("Saved %q: find it beside the notes, or press v in the todos", s.Name)
+		return m, m.saveSavedSearchCmd(s)
+	}
+
+	var cmd tea.Cmd
+	m.saveSearchInput, cmd = m.saveSearchInput.Update(msg)
+	return m, cmd
+}
+
+// openSavedSearches lists the saved searches to filter the todos by.
+func (m MainModel) openSavedSearches() (tea.Model, tea.Cmd) {
+	if len(m.savedSearches) == 0 {
+		m.status = "No saved searches yet: search with /, then press ctrl+s to save one"
+		return m, nil
+	}
+	m.state = SavedSearchView
+	m.updateSavedListItems()
+	m.savedList.ResetFilter()
+	m.savedList.Select(0)
+	for i, item := range m.savedList.Items() {
+		if item.(savedSearchItem).search.ID == m.todoSearch {
+			m.savedList.Select(i)
+		}
+	}
+	return m, nil
+}
+
+func (m MainModel) updateSavedSearches(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.state = TodoListView
+		return m, nil
+	case key.Matches(msg, m.keys.Enter):
+		if item, ok := m.savedList.SelectedItem().(savedSearchItem); ok {
+			m.todoSearch = item.search.ID
+			m.state = TodoListView
+			m.updateTodoListItems()
+			m.todoList.Select(0)
+		}
+		return m, nil
+	case key.Matches(msg, m.keys.Delete):
+		if item, ok := m.savedList.SelectedItem().(savedSearchItem); ok && item.search.ID != "" {
+			return m.confirmDeleteSavedSearch(item.search.ID)
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.savedList, cmd = m.savedList.Update(msg)
+	return m, cmd
+}
+
+func (m MainModel) confirmDeleteSavedSearch(id string) (tea.Model, tea.Cmd) {
+	m.itemToDeleteID = id
+	m.itemToDeleteType = itemTypeSearch
+	m.savedDeleteOrigin = m.state
+	m.state = DeleteConfirmView
+	return m, nil
+}
+
+func (m MainModel) deleteSavedSearchCmd(id string) tea.Msg {
+	return itemDeletedMsg{m.store.(storage.SavedSearches).DeleteSavedSearch(id)}
+}
+
+func (m *MainModel) updateSavedListItems() {
+	items := []list.Item{savedSearchItem{search: model.SavedSearch{Name: "All todos"}, count: len(m.todos)}}
+	for _, s := range m.savedSearches {
+		items = append(items, savedSearchItem{s, m.savedCounts[s.ID].todos})
+	}
+	m.savedList.SetItems(items)
+}
+
+// savedSearchTitle is the part of a list title naming the saved search
+// the list is narrowed to.
+func (m MainModel) savedSearchTitle(id string) string {
+	if s, ok := m.savedSearch(id); ok {
+		return " · ⌕ " + s.Name
+	}
+	return ""
+}
+
+func (m MainModel) savedSearchHelp() []key.Binding {
+	pick := m.keys.Enter
+	pick.SetHelp("enter", "filter todos")
+	return []key.Binding{pick, m.keys.Delete, m.keys.Up, m.keys.Down, m.keys.Back}
+}
+
+type savedSearchItem struct {
+	search model.SavedSearch
+	count  int
+}
+
+func (s savedSearchItem) FilterValue() string { return s.search.Name }
+func (s savedSearchItem) Title() string {
+	if s.search.ID == "" {
+		return s.search.Name
+	}
+	return "⌕ " + s.search.Name
+}
+func (s savedSearchItem) Description() string {
+	desc := fmt.Sprintf("%d todos", s.count)
+	if s.search.Query != "" {
+		desc += " · " + s.search.Query
+	}
+	return desc
+}
@@ -0,0 +1,238 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mtix28/noteme/model"
+	"github.com/mtix28/noteme/query"
+	"github.com/mtix28/noteme/search"
+)
+
+// Search view: ranked full-text search over every note and todo, opened
+// with / from any list. Queries can narrow results by field, see package
+// query; while a query does not parse, the error is shown over the last
+// results. Enter jumps to the result in its list, ctrl+s saves the query
+// (see savedsearches.go); esc goes back to where search was opened.
+
+// searchLimit caps the results kept for a query.
+const searchLimit = 50
+
+// snippetWidth is how much of a note's content is shown under a result.
+const snippetWidth = 80
+
+// reindex rebuilds the search index after notes, todos or projects were
+// loaded.
+func (m *MainModel) reindex() {
+	docs := make([]query.Item, 0, len(m.notes)+len(m.todos))
+	for _, n := range m.notes {
+		docs = append(docs, query.Note(n))
+	}
+	for _, t := range m.todos {
+		docs = append(docs, query.Todo(t, m.projectName(t.ProjectID)))
+	}
+	m.searchIndex = search.NewIndex(docs)
+	m.countSavedSearches()
+	if m.state == SearchView {
+		m.runSearch()
+	}
+}
+
+// showsList reports whether the current view is one of the lists search
+// can be opened from.
+func (m MainModel) showsList() bool {
+	switch m.state {
+	case NoteListView, TodoListView, TrashView, TagView, ProjectView:
+		return true
+	}
+	return false
+}
+
+func (m MainModel) openSearch() (tea.Model, tea.Cmd) {
+	m.searchOrigin = m.state
+	m.state = SearchView
+	m.folderFocus = false
+	m.searchInput.CursorEnd()
+	m.searchInput.Focus()
+	m.runSearch()
+	return m, nil
+}
+
+func (m *MainModel) runSearch() {
+	q, err := query.Parse(m.searchInput.Value(), time.Now())
+	m.searchErr = err
+	if err != nil {
+		return
+	}
+	m.searchResults = m.searchIndex.Search(q, searchLimit)
+	m.searchCursor = min(m.searchCursor, max(len(m.searchResults)-1, 0))
+}
+
+func (m MainModel) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.state = m.searchOrigin
+		return m, nil
+	case key.Matches(msg, m.keys.Enter):
+		if m.searchCursor < len(m.searchResults) {
+			return m.jumpTo(m.searchResults[m.searchCursor].Item)
+		}
+		return m, nil
+	case key.Matches(msg, m.keys.Save):
+		return m.startSaveSearch()
+	case msg.Type == tea.KeyUp || msg.Type == tea.KeyCtrlP:
+		m.searchCursor = max(m.searchCursor-1, 0)
+		return m, nil
+	case msg.Type == tea.KeyDown || msg.Type == tea.KeyCtrlN:
+		m.searchCursor = min(m.searchCursor+1, max(len(m.searchResults)-1, 0))
+		return m, nil
+	}
+
+	text := m.searchInput.Value()
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	if m.searchInput.Value() != text {
+		m.searchCursor = 0
+		m.runSearch()
+	}
+	return m, cmd
+}
+
+// jumpTo shows the note or todo in its list and selects it, lifting any
+// filter that hides it.
+func (m MainModel) jumpTo(doc query.Item) (tea.Model, tea.Cmd) {
+	switch doc.Kind {
+	case query.KindNote:
+		for _, n := range m.notes {
+			if n.ID != doc.ID {
+				continue
+			}
+			if !model.InFolder(n.Folder, m.folderFilter) || !model.MatchTags(n.TagSet(), m.tagFilter, m.tagMatchAll) || !m.savedQuery(m.noteSearch).Match(doc) {
+				m.folderFilter, m.tagFilter, m.noteSearch = "", nil, ""
+			}
+			break
+		}
+		m.state = NoteListView
+		m.noteList.ResetFilter()
+		m.updateNoteListItems()
+		m.selectNote(doc.ID)
+
+	case query.KindTodo:
+		i := indexOfTodo(m.todos, doc.ID)
+		if i < 0 {
+			return m, nil
+		}
+		t := m.todos[i]
+		if m.projectFilter != "" && t.ProjectID != m.projectFilter || !model.MatchTags(t.Tags, m.tagFilter, m.tagMatchAll) || !m.savedQuery(m.todoSearch).Match(doc) {
+			m.projectFilter, m.tagFilter, m.todoSearch = "", nil, ""
+		}
+		// Unfold the todos above it.
+		seen := map[string]bool{}
+		for p := indexOfTodo(m.todos, t.ParentID); p >= 0 && !seen[m.todos[p].ID]; p = indexOfTodo(m.todos, m.todos[p].ParentID) {
+			seen[m.todos[p].ID] = true
+			delete(m.collapsed, m.todos[p].ID)
+		}
+		m.state = TodoListView
+		m.todoList.ResetFilter()
+		m.updateTodoListItems()
+		m.selectTodo(doc.ID)
+	}
+	return m, nil
+}
+
+func indexOfTodo(todos []model.Todo, id string) int {
+	for i, t := range todos {
+		if id != "" && t.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// selectNote moves the note list's cursor to the note with the given ID.
+func (m *MainModel) selectNote(id string) {
+	for i, item := range m.noteList.VisibleItems() {
+		if n, ok := item.(noteItem); ok && n.note.ID == id {
+			m.noteList.Select(i)
+			return
+		}
+	}
+}
+
+func (m MainModel) renderSearch() string {
+	summary := fmt.Sprintf("%d results", len(m.searchResults))
+	if len(m.searchResults) == 1 {
+		summary = "1 result"
+	}
+	switch {
+	case strings.TrimSpace(m.searchInput.Value()) == "":
+		summary = fmt.Sprintf("Searching %d notes and todos", m.searchIndex.Len())
+	case len(m.searchResults) == searchLimit:
+		summary = fmt.Sprintf("Best %d results", searchLimit)
+	}
+	summary = statLabel.Render(summary)
+	if m.searchErr != nil {
+		summary = searchErrorStyle.Render(m.searchErr.Error())
+	}
+
+	// Every result takes three lines; scroll to keep the cursor in view.
+	_, v := appStyle.GetFrameSize()
+	fit := max((m.height-v-9)/3, 1)
+	first := max(0, min(m.searchCursor-fit/2, len(m.searchResults)-fit))
+
+	lines := []string{titleStyle.Render("Search"), m.searchInput.View(), summary, ""}
+	for i := first; i < len(m.searchResults) && i < first+fit; i++ {
+		r := m.searchResults[i]
+		cursor := "  "
+		if i == m.searchCursor {
+			cursor = searchCursorStyle.Render("▌ ")
+		}
+		title := highlight(r.Title, search.Marks(r.Title, r.Terms))
+		if r.Kind == query.KindTodo && r.Done {
+			title = "[x] " + title
+		}
+		lines = append(lines, cursor+statLabel.Render(fmt.Sprintf("%-5s", r.Kind))+title)
+
+		var detail []string
+		if r.Folder != "" {
+			detail = append(detail, "["+highlight(r.Folder, search.Marks(r.Folder, r.Terms))+"]")
+		}
+		if r.Project != "" {
+			detail = append(detail, highlight("+"+r.Project, search.Marks("+"+r.Project, r.Terms)))
+		}
+		if tags := tagsLabel(r.Tags); tags != "" {
+			detail = append(detail, highlight(tags, search.Marks(tags, r.Terms)))
+		}
+		if snippet, marks := r.Snippet(snippetWidth); snippet != "" {
+			detail = append(detail, highlight(snippet, marks))
+		}
+		lines = append(lines, "       "+strings.Join(detail, " "), "")
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// highlight renders the marked parts of text in the match style.
+func highlight(text string, marks []search.Span) string {
+	var sb strings.Builder
+	at := 0
+	for _, s := range marks {
+		sb.WriteString(text[at:s.Start])
+		sb.WriteString(searchMatchStyle.Render(text[s.Start:s.End]))
+		at = s.End
+	}
+	sb.WriteString(text[at:])
+	return sb.String()
+}
+
+func (m MainModel) searchHelp() []key.Binding {
+	move := key.NewBinding(key.WithKeys("up", "down"), key.WithHelp("↑/↓", "pick"))
+	jump := m.keys.Enter
+	jump.SetHelp("enter", "go to")
+	save := m.keys.Save
+	save.SetHelp("ctrl+s", "save search")
+	return []key.Binding{move, jump, save, m.keys.Back}
+}
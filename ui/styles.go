@@ -8,6 +8,13 @@ var (
 	subtleColor    = lipgloss.Color("#6272A4") // Gray-ish
 	accentColor    = lipgloss.Color("#50FA7B") // Green
     textColor      = lipgloss.Color("#F8F8F2") // White
+    dangerColor    = lipgloss.Color("#FF5555") // Red
+
+    // Todo priorities
+    priorityLowColor    = lipgloss.Color("#8BE9FD") // Cyan
+    priorityMediumColor = lipgloss.Color("#F1FA8C") // Yellow
+    priorityHighColor   = lipgloss.Color("#FFB86C") // Orange
+    priorityUrgentColor = dangerColor
     
     // Heatmap Colors (activity levels)
     heatLevel0 = lipgloss.Color("#282A36") // None
@@ -50,7 +57,39 @@ var (
         Foreground(accentColor).
         Bold(true).
         MarginLeft(1)
+
+    overdueStyle = statValue.Foreground(dangerColor)
+
+    progressFillStyle  = lipgloss.NewStyle().Foreground(accentColor)
+    progressTrackStyle = lipgloss.NewStyle().Foreground(subtleColor)
+
+    sidebarStyle = lipgloss.NewStyle().
+        Width(sidebarWidth - 2).
+        Border(lipgloss.NormalBorder(), false, true, false, false).
+        BorderForeground(subtleColor).
+        MarginRight(1)
+
+    folderCursorStyle = lipgloss.NewStyle().Foreground(secondaryColor).Bold(true)
+    folderActiveStyle = lipgloss.NewStyle().Foreground(primaryColor).Bold(true)
+
+    searchMatchStyle  = lipgloss.NewStyle().Foreground(secondaryColor).Bold(true)
+    searchCursorStyle = lipgloss.NewStyle().Foreground(primaryColor)
+    searchErrorStyle  = lipgloss.NewStyle().Foreground(dangerColor)
         
+    diffBoxStyle = lipgloss.NewStyle().
+        Border(lipgloss.RoundedBorder()).
+        BorderForeground(subtleColor).
+        Padding(0, 1)
+
+    previewBoxStyle = diffBoxStyle
+
+    diffInsertStyle = lipgloss.NewStyle().Foreground(accentColor)
+    diffDeleteStyle = lipgloss.NewStyle().Foreground(dangerColor)
+    diffMetaStyle   = lipgloss.NewStyle().Foreground(subtleColor)
+
+    statusStyle = lipgloss.NewStyle().
+        Foreground(secondaryColor)
+
     emptyStateStyle = lipgloss.NewStyle().
         Foreground(subtleColor).
         Italic(true).
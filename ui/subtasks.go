@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mtix28/noteme/model"
+)
+
+// Subtasks: the todo list is shown as a tree, see model.Tree. Todos with
+// subtasks can be folded, and todos can be indented under the one above,
+// outdented and moved among their siblings.
+
+// startNewSubtask opens the todo form for a new subtask of parent.
+func (m MainModel) startNewSubtask(parent model.Todo) (tea.Model, tea.Cmd) {
+	res, cmd := m.startNewTodo()
+	m = res.(MainModel)
+	m.newTodoParent = parent
+	return m, cmd
+}
+
+// addTodo adds a todo made in the todo form, as a subtask if the form was
+// opened for one.
+func (m *MainModel) addTodo(t model.Todo) tea.Cmd {
+	if p := m.newTodoParent; p.ID != "" {
+		t.ParentID = p.ID
+		t.Position = model.NextPosition(m.todos, p.ID)
+		if t.ProjectID == "" {
+			t.ProjectID = p.ProjectID
+		}
+		delete(m.collapsed, p.ID)
+		m.newTodoParent = model.Todo{}
+	}
+	if t.ProjectID == "" {
+		t.ProjectID = m.projectFilter
+	}
+	m.todos = append([]model.Todo{t}, m.todos...)
+	m.updateTodoListItems()
+	return m.saveTodoCmd(t)
+}
+
+// toggleTodo checks the selected todo off, or reopens it, along with any
+// parents that follow it.
+func (m MainModel) toggleTodo() (tea.Model, tea.Cmd) {
+	i := m.selectedTodoIndex()
+	if i < 0 {
+		return m, nil
+	}
+	changed := model.SetTodoDone(m.todos, m.todos[i].ID, !m.todos[i].Done, time.Now())
+	if len(changed) > 1 {
+		if changed[0].Done {
+			m.status = fmt.Sprintf("All subtasks done, checked off %q", changed[len(changed)-1].Content)
+		} else {
+			m.status = fmt.Sprintf("Reopened %q", changed[len(changed)-1].Content)
+		}
+	}
+	return m, m.saveTodosCmd(changed)
+}
+
+// reshapeTodos applies a tree edit (model.MoveTodo and friends) to the
+// selected todo and keeps it selected.
+func (m MainModel) reshapeTodos(edit func(todos []model.Todo, id string) []model.Todo) (tea.Model, tea.Cmd) {
+	i := m.selectedTodoIndex()
+	if i < 0 {
+		return m, nil
+	}
+	id := m.todos[i].ID
+	changed := edit(m.todos, id)
+	if len(changed) == 0 {
+		return m, nil
+	}
+	m.updateTodoListItems()
+	m.selectTodo(id)
+	return m, m.saveTodosCmd(changed)
+}
+
+// moveTodo moves the selected todo up or down among its siblings. Only
+// subtasks keep a hand-made order unless the list is sorted that way.
+func (m MainModel) moveTodo(delta int) (tea.Model, tea.Cmd) {
+	i := m.selectedTodoIndex()
+	if i < 0 {
+		return m, nil
+	}
+	if m.todoSort != sortByHand && !m.hasParent(m.todos[i]) {
+		m.status = "Top-level todos follow the sort order; press s to order them by hand"
+		return m, nil
+	}
+	return m.reshapeTodos(func(todos []model.Todo, id string) []model.Todo {
+		return model.MoveTodo(todos, id, delta)
+	})
+}
+
+// toggleFold folds or unfolds the subtasks of the selected todo.
+func (m MainModel) toggleFold() (tea.Model, tea.Cmd) {
+	item, ok := m.todoList.SelectedItem().(todoItem)
+	if !ok || item.subtasks == 0 {
+		return m, nil
+	}
+	if m.collapsed[item.todo.ID] {
+		delete(m.collapsed, item.todo.ID)
+	} else {
+		m.collapsed[item.todo.ID] = true
+	}
+	m.updateTodoListItems()
+	m.selectTodo(item.todo.ID)
+	return m, nil
+}
+
+func (m MainModel) hasParent(t model.Todo) bool {
+	for _, p := range m.todos {
+		if p.ID == t.ParentID {
+			return true
+		}
+	}
+	return false
+}
+
+func (m MainModel) saveTodosCmd(todos []model.Todo) tea.Cmd {
+	cmds := make([]tea.Cmd, len(todos))
+	for i, t := range todos {
+		cmds[i] = m.saveTodoCmd(t)
+	}
+	return tea.Batch(cmds...)
+}
+
+// treePrefix indents a todo by its depth and marks whether its subtasks
+// are folded.
+func (t todoItem) treePrefix() string {
+	prefix := strings.Repeat("  ", t.depth)
+	switch {
+	case t.subtasks == 0:
+		return prefix
+	case t.collapsed:
+		return prefix + "▸ "
+	}
+	return prefix + "▾ "
+}
+
+// progressLabel is the share of finished subtasks, like "2/5".
+func (t todoItem) progressLabel() string {
+	if t.subtasks == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", t.finished, t.subtasks)
+}
@@ -0,0 +1,174 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Tag browser: every tag used by a note or todo with its counts. Picking
+// tags here filters both the note and the todo list, matching any or all of
+// them.
+
+// openTags shows the tag browser, coming from view origin.
+func (m MainModel) openTags(origin sessionState) (tea.Model, tea.Cmd) {
+	if origin != TodoListView {
+		origin = NoteListView
+	}
+	m.tagOrigin = origin
+	m.tagPicked = map[string]bool{}
+	for _, t := range m.tagFilter {
+		m.tagPicked[t] = true
+	}
+	m.tagPickAll = m.tagMatchAll
+	m.state = TagView
+	m.updateTagListItems()
+	m.tagList.Select(0)
+	return m, nil
+}
+
+func (m MainModel) updateTags(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.state = m.tagOrigin
+		return m, nil
+	case key.Matches(msg, m.keys.Toggle):
+		if item, ok := m.tagList.SelectedItem().(tagItem); ok {
+			m.tagPicked[item.name] = !m.tagPicked[item.name]
+			m.updateTagListItems()
+		}
+		return m, nil
+	case key.Matches(msg, m.keys.TagMode):
+		m.tagPickAll = !m.tagPickAll
+		m.updateTagListItems()
+		return m, nil
+	case key.Matches(msg, m.keys.Clear):
+		m.tagPicked = map[string]bool{}
+		return m.applyTagFilter()
+	case key.Matches(msg, m.keys.Enter):
+		return m.applyTagFilter()
+	}
+
+	var cmd tea.Cmd
+	m.tagList, cmd = m.tagList.Update(msg)
+	return m, cmd
+}
+
+// applyTagFilter filters the lists by the picked tags and goes back.
+func (m MainModel) applyTagFilter() (tea.Model, tea.Cmd) {
+	m.tagFilter = nil
+	for t, picked := range m.tagPicked {
+		if picked {
+			m.tagFilter = append(m.tagFilter, t)
+		}
+	}
+	sort.Strings(m.tagFilter)
+	m.tagMatchAll = m.tagPickAll
+	m.updateNoteListItems()
+	m.updateTodoListItems()
+	m.state = m.tagOrigin
+	return m, nil
+}
+
+// tagCounts counts the notes and todos carrying each tag.
+func (m MainModel) tagCounts() map[string]*tagItem {
+	counts := map[string]*tagItem{}
+	get := func(t string) *tagItem {
+		if counts[t] == nil {
+			counts[t] = &tagItem{name: t}
+		}
+		return counts[t]
+	}
+	for _, n := range m.notes {
+		for _, t := range n.TagSet() {
+			get(t).notes++
+		}
+	}
+	for _, td := range m.todos {
+		for _, t := range td.Tags {
+			get(t).todos++
+		}
+	}
+	return counts
+}
+
+func (m *MainModel) updateTagListItems() {
+	counts := m.tagCounts()
+	// Keep picked tags listed even if nothing uses them any more, so they
+	// can be unpicked.
+	for t, picked := range m.tagPicked {
+		if picked && counts[t] == nil {
+			counts[t] = &tagItem{name: t}
+		}
+	}
+	names := make([]string, 0, len(counts))
+	for t := range counts {
+		names = append(names, t)
+	}
+	sort.Strings(names)
+
+	items := make([]list.Item, len(names))
+	for i, t := range names {
+		item := *counts[t]
+		item.picked = m.tagPicked[t]
+		items[i] = item
+	}
+	m.tagList.SetItems(items)
+	m.tagList.Title = "Tags (match " + matchMode(m.tagPickAll) + ")"
+}
+
+// filterTitle is the list title with the active tag filter, if any.
+func (m MainModel) filterTitle(title string) string {
+	if len(m.tagFilter) == 0 {
+		return title
+	}
+	op := " OR "
+	if m.tagMatchAll {
+		op = " AND "
+	}
+	return title + " · #" + strings.Join(m.tagFilter, op+"#")
+}
+
+func (m MainModel) tagHelp() []key.Binding {
+	pick := m.keys.Toggle
+	pick.SetHelp("space", "pick")
+	apply := m.keys.Enter
+	apply.SetHelp("enter", "filter")
+	return []key.Binding{pick, m.keys.TagMode, apply, m.keys.Clear, m.keys.Back}
+}
+
+func matchMode(all bool) string {
+	if all {
+		return "all"
+	}
+	return "any"
+}
+
+// tagsLabel formats tags as "#a #b".
+func tagsLabel(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return "#" + strings.Join(tags, " #")
+}
+
+type tagItem struct {
+	name         string
+	notes, todos int
+	picked       bool
+}
+
+func (t tagItem) FilterValue() string { return t.name }
+func (t tagItem) Title() string {
+	if t.picked {
+		return "[x] #" + t.name
+	}
+	return "[ ] #" + t.name
+}
+func (t tagItem) Description() string {
+	return fmt.Sprintf("%d notes · %d todos", t.notes, t.todos)
+}
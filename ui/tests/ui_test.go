@@ -0,0 +1,715 @@
+package ui_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/exp/teatest"
+	"github.com/mtix28/noteme/model"
+	"github.com/mtix28/noteme/storage"
+	"github.com/mtix28/noteme/ui"
+)
+
+// These tests drive the whole app through a real Bubble Tea program and
+// then check what ended up on disk, reading it back with a fresh Storage.
+
+var (
+	tab   = tea.KeyMsg{Type: tea.KeyTab}
+	enter = tea.KeyMsg{Type: tea.KeyEnter}
+	space = tea.KeyMsg{Type: tea.KeySpace}
+	esc   = tea.KeyMsg{Type: tea.KeyEsc}
+)
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// startApp seeds a data directory and starts the app on it.
+func startApp(t *testing.T, notes []model.Note, todos []model.Todo) (*teatest.TestModel, string) {
+	t.Helper()
+	dir := t.TempDir()
+	s, err := storage.NewStorageAt(dir)
+	if err != nil {
+		t.Fatalf("NewStorageAt: %v", err)
+	}
+	if err := s.SaveNotes(notes); err != nil {
+		t.Fatalf("SaveNotes: %v", err)
+	}
+	if err := s.SaveTodos(todos); err != nil {
+		t.Fatalf("SaveTodos: %v", err)
+	}
+
+	tm := teatest.NewTestModel(t, ui.NewModel(s), teatest.WithInitialTermSize(100, 40))
+	t.Cleanup(func() { tm.Quit() })
+	return tm, dir
+}
+
+// waitForScreen waits until the rendered output contains all of want.
+func waitForScreen(t *testing.T, tm *teatest.TestModel, want ...string) {
+	t.Helper()
+	teatest.WaitFor(t, tm.Output(), func(out []byte) bool {
+		for _, w := range want {
+			if !bytes.Contains(out, []byte(w)) {
+				return false
+			}
+		}
+		return true
+	}, teatest.WithDuration(3*time.Second))
+}
+
+// waitForDisk polls the data directory until check passes.
+func waitForDisk(t *testing.T, dir string, check func(*storage.Storage) bool) {
+	t.Helper()
+	s, err := storage.NewStorageAt(dir)
+	if err != nil {
+		t.Fatalf("NewStorageAt: %v", err)
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for !check(s) {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the data directory")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func loadTodos(t *testing.T, dir string) map[string]model.Todo {
+	t.Helper()
+	s, _ := storage.NewStorageAt(dir)
+	todos, err := s.LoadTodos()
+	if err != nil {
+		t.Fatalf("LoadTodos: %v", err)
+	}
+	byID := map[string]model.Todo{}
+	for _, todo := range todos {
+		byID[todo.ID] = todo
+	}
+	return byID
+}
+
+var threeTodos = []model.Todo{
+	{ID: "a", Content: "alpha chore", Frequency: model.Once},
+	{ID: "b", Content: "beta chore", Frequency: model.Once},
+	{ID: "g", Content: "gamma chore", Frequency: model.Once},
+}
+
+func openTodos(t *testing.T, tm *teatest.TestModel) {
+	t.Helper()
+	tm.Send(tab) // notes
+	tm.Send(tab) // todos
+	waitForScreen(t, tm, "alpha chore", "gamma chore")
+}
+
+func filterList(tm *teatest.TestModel, query string) {
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlF})
+	tm.Type(query)
+	tm.Send(enter) // apply the filter
+}
+
+func TestToggleInFilteredTodoList(t *testing.T) {
+	tm, dir := startApp(t, nil, threeTodos)
+	openTodos(t, tm)
+
+	filterList(tm, "gamma")
+	tm.Send(space)
+
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		g, err := s.GetTodo("g")
+		return err == nil && g.Done
+	})
+	todos := loadTodos(t, dir)
+	if todos["a"].Done || todos["b"].Done {
+		t.Fatalf("Expected only gamma to be done, got %+v", todos)
+	}
+}
+
+func TestDeleteInFilteredTodoList(t *testing.T) {
+	tm, dir := startApp(t, nil, threeTodos)
+	openTodos(t, tm)
+
+	filterList(tm, "beta")
+	tm.Send(runes("d"))
+	waitForScreen(t, tm, "Move this todo to the trash?")
+	tm.Send(runes("y"))
+
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		_, err := s.GetTodo("b")
+		return err != nil
+	})
+	todos := loadTodos(t, dir)
+	if _, ok := todos["a"]; !ok {
+		t.Fatal("Expected alpha to survive")
+	}
+	if _, ok := todos["g"]; !ok {
+		t.Fatal("Expected gamma to survive")
+	}
+
+	s, _ := storage.NewStorageAt(dir)
+	trashed, err := s.TrashedItems()
+	if err != nil || len(trashed) != 1 || trashed[0].ID != "b" {
+		t.Fatalf("Expected beta in the trash, got %+v, %v", trashed, err)
+	}
+}
+
+func TestTypingInFilterDoesNotTriggerActions(t *testing.T) {
+	tm, dir := startApp(t, nil, threeTodos)
+	openTodos(t, tm)
+
+	// "d", "n", "e", space and "q" are all bound in the todo list, but
+	// while the filter prompt is open they are just text.
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlF})
+	tm.Type("dne q")
+	tm.Send(esc)
+	tm.Send(runes(" "))
+
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		todos, _ := s.LoadTodos()
+		for _, todo := range todos {
+			if todo.Done {
+				return true
+			}
+		}
+		return false
+	})
+	todos := loadTodos(t, dir)
+	if len(todos) != 3 {
+		t.Fatalf("Expected all three todos to survive, got %+v", todos)
+	}
+	if !todos["a"].Done || todos["b"].Done || todos["g"].Done {
+		t.Fatalf("Expected the first todo to be toggled once the filter was closed, got %+v", todos)
+	}
+}
+
+func TestDeleteInFilteredNoteList(t *testing.T) {
+	now := time.Now()
+	notes := []model.Note{
+		{ID: "n1", Title: "Groceries", CreatedAt: now},
+		{ID: "n2", Title: "Meeting notes", CreatedAt: now.Add(-time.Hour)},
+	}
+	tm, dir := startApp(t, notes, threeTodos)
+	tm.Send(tab)
+	waitForScreen(t, tm, "Groceries", "Meeting notes")
+
+	filterList(tm, "meeting")
+	tm.Send(runes("x"))
+	tm.Send(runes("y"))
+
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		_, err := s.GetNote("n2")
+		return err != nil
+	})
+	s, _ := storage.NewStorageAt(dir)
+	if _, err := s.GetNote("n1"); err != nil {
+		t.Fatalf("Expected Groceries to survive: %v", err)
+	}
+}
+
+func TestTagFilterAllOf(t *testing.T) {
+	todos := []model.Todo{
+		{ID: "a", Content: "alpha chore", Frequency: model.Once, Tags: []string{"home"}},
+		{ID: "b", Content: "beta chore", Frequency: model.Once, Tags: []string{"home", "work"}},
+		{ID: "g", Content: "gamma chore", Frequency: model.Once, Tags: []string{"work"}},
+	}
+	tm, dir := startApp(t, nil, todos)
+	openTodos(t, tm)
+
+	tm.Send(runes("#"))
+	waitForScreen(t, tm, "#home", "#work", "0 notes · 2 todos")
+	tm.Send(space) // home
+	tm.Send(runes("j"))
+	tm.Send(space)      // work
+	tm.Send(runes("m")) // match all
+	tm.Send(enter)
+	waitForScreen(t, tm, "Todos · #home AND #work")
+	tm.Send(space)
+
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		b, err := s.GetTodo("b")
+		return err == nil && b.Done
+	})
+	got := loadTodos(t, dir)
+	if got["a"].Done || got["g"].Done {
+		t.Fatalf("Expected only beta to be done, got %+v", got)
+	}
+}
+
+func TestRaisePriorityAndSort(t *testing.T) {
+	tm, dir := startApp(t, nil, threeTodos)
+	openTodos(t, tm)
+
+	// Raise gamma to medium, then sort by priority: it moves to the top,
+	// so space checks it off.
+	tm.Send(runes("j"))
+	tm.Send(runes("j"))
+	tm.Send(runes("+"))
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		g, err := s.GetTodo("g")
+		return err == nil && g.Priority == model.PriorityLow
+	})
+	tm.Send(runes("+"))
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		g, err := s.GetTodo("g")
+		return err == nil && g.Priority == model.PriorityMedium
+	})
+	tm.Send(runes("s"))
+	waitForScreen(t, tm, "Todos by priority")
+	tm.Send(space)
+
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		g, err := s.GetTodo("g")
+		return err == nil && g.Done
+	})
+	got := loadTodos(t, dir)
+	if got["a"].Done || got["b"].Done {
+		t.Fatalf("Expected only gamma to be done, got %+v", got)
+	}
+}
+
+func TestFinishingSubtasksCompletesParent(t *testing.T) {
+	todos := []model.Todo{
+		{ID: "trip", Content: "plan trip", Frequency: model.Once},
+		{ID: "pack", Content: "pack bags", Frequency: model.Once, ParentID: "trip"},
+	}
+	tm, dir := startApp(t, nil, todos)
+	tm.Send(tab)
+	tm.Send(tab)
+	waitForScreen(t, tm, "plan trip 0/1", "pack bags")
+
+	// Add a second step under the trip, then check both off.
+	tm.Send(runes("a"))
+	waitForScreen(t, tm, `New Subtask of "plan trip"`)
+	tm.Type("book hotel")
+	tm.Send(enter)
+	waitForScreen(t, tm, "plan trip 0/2", "book hotel")
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		todos, _ := s.LoadTodos()
+		return len(todos) == 3
+	})
+
+	tm.Send(runes("j"))
+	tm.Send(space)
+	waitForScreen(t, tm, "plan trip 1/2")
+	tm.Send(runes("j"))
+	tm.Send(space)
+
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		trip, err := s.GetTodo("trip")
+		return err == nil && trip.Done
+	})
+	for _, todo := range loadTodos(t, dir) {
+		if !todo.Done {
+			t.Fatalf("Expected every todo to be done, got %+v", todo)
+		}
+		if todo.ID != "trip" && todo.ParentID != "trip" {
+			t.Fatalf("Expected %q to be a subtask of the trip", todo.Content)
+		}
+	}
+}
+
+func TestQuickAddProjectAndDrillIn(t *testing.T) {
+	tm, dir := startApp(t, nil, threeTodos)
+	openTodos(t, tm)
+
+	tm.Send(runes("n"))
+	tm.Type("book flights +holiday")
+	tm.Send(enter)
+	waitForScreen(t, tm, "+holiday")
+
+	var project model.Project
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		projects, _ := s.LoadProjects()
+		if len(projects) != 1 {
+			return false
+		}
+		project = projects[0]
+		todos, _ := s.LoadTodos()
+		for _, todo := range todos {
+			if todo.Content == "book flights" {
+				return todo.ProjectID == project.ID
+			}
+		}
+		return false
+	})
+	if project.Name != "holiday" {
+		t.Fatalf("Expected a project called holiday, got %+v", project)
+	}
+
+	// Todos added while drilled into the project join it.
+	tm.Send(tab)
+	waitForScreen(t, tm, "1 open · 0 done")
+	tm.Send(enter)
+	waitForScreen(t, tm, "Todos · +holiday")
+	tm.Send(runes("n"))
+	tm.Type("renew passport")
+	tm.Send(enter)
+
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		todos, _ := s.LoadTodos()
+		for _, todo := range todos {
+			if todo.Content == "renew passport" {
+				return todo.ProjectID == project.ID
+			}
+		}
+		return false
+	})
+}
+
+func TestRenameFolderAndMoveNote(t *testing.T) {
+	notes := []model.Note{
+		{ID: "n1", Title: "Acme kickoff", Folder: "work/clients/acme"},
+		{ID: "n2", Title: "Globex call", Folder: "work/clients/globex"},
+		{ID: "n3", Title: "Standup", Folder: "daily"},
+	}
+	tm, dir := startApp(t, notes, nil)
+	tm.Send(tab)
+	waitForScreen(t, tm, "Folders", "Standup")
+
+	// All notes, daily, work, clients: rename clients with its subfolders.
+	tm.Send(runes("f"))
+	for range 3 {
+		tm.Send(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	tm.Send(runes("R"))
+	waitForScreen(t, tm, "Rename Folder work/clients")
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlU})
+	tm.Type("customers")
+	tm.Send(enter)
+
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		n1, _ := s.GetNote("n1")
+		n2, _ := s.GetNote("n2")
+		return n1.Folder == "customers/acme" && n2.Folder == "customers/globex"
+	})
+	waitForScreen(t, tm, "customers")
+
+	// Move the standup note from daily to the folder above it in the picker.
+	tm.Send(runes("f"))
+	filterList(tm, "Standup")
+	tm.Send(runes("m"))
+	waitForScreen(t, tm, `Move "Standup" to`)
+	tm.Send(tea.KeyMsg{Type: tea.KeyUp})
+	tm.Send(enter)
+
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		n3, _ := s.GetNote("n3")
+		return n3.Folder == "customers/globex"
+	})
+}
+
+func TestSearchJumpsToResults(t *testing.T) {
+	notes := []model.Note{
+		{ID: "n1", Title: "Groceries", Content: "Buy oat milk and bread."},
+		{ID: "n2", Title: "Meeting notes", Content: "Nothing about milk here, just quotas."},
+	}
+	tm, dir := startApp(t, notes, threeTodos)
+	openTodos(t, tm)
+
+	// q is a letter in the search box, not quit.
+	tm.Send(runes("/"))
+	tm.Type("q")
+	waitForScreen(t, tm, "1 result")
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlU})
+	waitForScreen(t, tm, "Searching")
+	tm.Type("gamma")
+	waitForScreen(t, tm, "1 result")
+	tm.Send(enter)
+	tm.Send(space)
+
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		g, err := s.GetTodo("g")
+		return err == nil && g.Done
+	})
+	if todos := loadTodos(t, dir); todos["a"].Done || todos["b"].Done {
+		t.Fatalf("Expected only gamma to be done, got %+v", todos)
+	}
+
+	// Content is searched too, and every word has to match.
+	tm.Send(runes("/"))
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlU})
+	tm.Type("oat milk")
+	waitForScreen(t, tm, "Buy", "1 result")
+	tm.Send(enter)
+	tm.Send(runes("d"))
+	waitForScreen(t, tm, "Move this note to the trash?")
+	tm.Send(runes("y"))
+
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		_, err := s.GetNote("n1")
+		return err != nil
+	})
+	s, _ := storage.NewStorageAt(dir)
+	if _, err := s.GetNote("n2"); err != nil {
+		t.Fatalf("Expected the meeting notes to survive: %v", err)
+	}
+}
+
+func TestSearchQueryLanguage(t *testing.T) {
+	notes := []model.Note{
+		{ID: "n1", Title: "Budget meeting", Folder: "work"},
+		{ID: "n2", Title: "Budget for the holidays", Folder: "home"},
+	}
+	tm, dir := startApp(t, notes, nil)
+	tm.Send(tab)
+	waitForScreen(t, tm, "Budget meeting")
+
+	tm.Send(runes("/"))
+	tm.Type("budget folder:home created:>")
+	waitForScreen(t, tm, "created: needs a value")
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlW})
+	waitForScreen(t, tm, "1 result")
+	tm.Send(enter)
+	tm.Send(runes("d"))
+	waitForScreen(t, tm, "Move this note to the trash?")
+	tm.Send(runes("y"))
+
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		_, err := s.GetNote("n2")
+		return err != nil
+	})
+	s, _ := storage.NewStorageAt(dir)
+	if _, err := s.GetNote("n1"); err != nil {
+		t.Fatalf("Expected the work budget to survive: %v", err)
+	}
+}
+
+func TestSavedSearchFiltersNotesAndTodos(t *testing.T) {
+	notes := []model.Note{
+		{ID: "n1", Title: "Budget meeting", Folder: "work"},
+		{ID: "n2", Title: "Holiday plans", Folder: "home"},
+	}
+	tm, dir := startApp(t, notes, threeTodos)
+	tm.Send(tab)
+	waitForScreen(t, tm, "Budget meeting")
+
+	tm.Send(runes("/"))
+	tm.Type("budget OR chore -beta")
+	waitForScreen(t, tm, "3 results")
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlS})
+	waitForScreen(t, tm, "Save Search")
+	tm.Type("Chores")
+	tm.Send(enter)
+
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		searches, err := s.LoadSavedSearches()
+		return err == nil && len(searches) == 1 && searches[0].Name == "Chores" && searches[0].Query == "budget OR chore -beta"
+	})
+
+	// A smart folder in the sidebar, after All notes, home and work.
+	tm.Send(esc)
+	waitForScreen(t, tm, "Saved searches", "⌕ Chores")
+	tm.Send(runes("f"))
+	for range 3 {
+		tm.Send(runes("j"))
+	}
+	tm.Send(enter)
+	waitForScreen(t, tm, "Notes · ⌕ Chores")
+
+	// A filter for the todos, leaving out beta.
+	tm.Send(tab)
+	tm.Send(runes("v"))
+	waitForScreen(t, tm, "2 todos")
+	tm.Send(runes("j"))
+	tm.Send(enter)
+	waitForScreen(t, tm, "Todos · ⌕ Chores")
+	tm.Send(runes("j"))
+	tm.Send(space)
+
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		g, err := s.GetTodo("g")
+		return err == nil && g.Done
+	})
+	if todos := loadTodos(t, dir); todos["a"].Done || todos["b"].Done {
+		t.Fatalf("Expected only gamma to be done, got %+v", todos)
+	}
+}
+
+func TestReadAndPreviewMarkdown(t *testing.T) {
+	notes := []model.Note{{
+		ID:      "n1",
+		Title:   "Plan",
+		Folder:  "work",
+		Content: "# Goals\n\n- ship it\n\n| step | owner |\n|---|---|\n| draft | sam |",
+	}}
+	tm, dir := startApp(t, notes, nil)
+	tm.Send(tab)
+	waitForScreen(t, tm, "Plan")
+
+	// Read mode renders the note: the table gets drawn.
+	tm.Send(runes("p"))
+	waitForScreen(t, tm, "Goals", "ship it", "┼")
+
+	// On a wide terminal the editor previews the text as it is typed.
+	tm.Send(tea.WindowSizeMsg{Width: 160, Height: 40})
+	tm.Send(runes("e"))
+	waitForScreen(t, tm, "Edit Note", "Preview")
+	tm.Send(tab) // folder
+	tm.Send(tab) // content
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlEnd})
+	tm.Send(enter)
+	tm.Send(enter)
+	tm.Type("- [x] shipped")
+	waitForScreen(t, tm, "[✓]")
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlS})
+
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		n, err := s.GetNote("n1")
+		return err == nil && strings.HasSuffix(n.Content, "\n\n- [x] shipped")
+	})
+}
+
+func TestTypingQDoesNotQuit(t *testing.T) {
+	tm, dir := startApp(t, nil, threeTodos)
+	openTodos(t, tm)
+
+	tm.Send(tab)
+	waitForScreen(t, tm, "Projects")
+	tm.Send(runes("n"))
+	waitForScreen(t, tm, "New Project")
+	tm.Type("quiz night")
+	tm.Send(enter)
+
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		projects, _ := s.LoadProjects()
+		return len(projects) == 1 && projects[0].Name == "quiz-night"
+	})
+}
+
+func TestTypingQInFolderNameDoesNotQuit(t *testing.T) {
+	notes := []model.Note{{ID: "n1", Title: "Standup", Folder: "daily"}}
+	tm, dir := startApp(t, notes, nil)
+	tm.Send(tab)
+	waitForScreen(t, tm, "Folders", "Standup")
+
+	tm.Send(runes("f"))
+	tm.Send(tea.KeyMsg{Type: tea.KeyDown})
+	tm.Send(runes("R"))
+	waitForScreen(t, tm, "Rename Folder daily")
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlU})
+	tm.Type("quarterly")
+	tm.Send(enter)
+
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		n1, _ := s.GetNote("n1")
+		return n1.Folder == "quarterly"
+	})
+}
+
+func TestTypingQInSavedSearchNameDoesNotQuit(t *testing.T) {
+	tm, dir := startApp(t, nil, threeTodos)
+	openTodos(t, tm)
+
+	tm.Send(runes("/"))
+	tm.Type("chore")
+	waitForScreen(t, tm, "3 results")
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlS})
+	waitForScreen(t, tm, "Save Search")
+	tm.Type("quarterly")
+	tm.Send(enter)
+
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		searches, _ := s.LoadSavedSearches()
+		return len(searches) == 1 && searches[0].Name == "quarterly"
+	})
+}
+
+func TestHistoryDiffFollowsSelection(t *testing.T) {
+	note := model.Note{ID: "n1", Title: "Plan", Folder: "work", Content: "three"}
+	tm, dir := startApp(t, []model.Note{note}, nil)
+	s, err := storage.NewStorageAt(dir)
+	if err != nil {
+		t.Fatalf("NewStorageAt: %v", err)
+	}
+	saved := time.Date(2026, 1, 1, 9, 0, 0, 0, time.Local)
+	for i, content := range []string{"one", "two"} {
+		old := note
+		old.Content = content
+		if err := s.AddRevision(model.NewRevision(content, old, saved.Add(time.Duration(i)*time.Hour))); err != nil {
+			t.Fatalf("AddRevision: %v", err)
+		}
+	}
+
+	tm.Send(tab)
+	waitForScreen(t, tm, "Plan")
+	tm.Send(runes("H"))
+	waitForScreen(t, tm, "History: Plan", "-two", "+three")
+
+	// Each revision gets its own diff, including when going back to one
+	// whose diff was already worked out.
+	tm.Send(runes("j"))
+	waitForScreen(t, tm, "-one")
+	tm.Send(runes("k"))
+	waitForScreen(t, tm, "-two")
+}
+
+func TestMoveNoteToNewFolder(t *testing.T) {
+	notes := []model.Note{{ID: "n1", Title: "Standup", Folder: "daily"}}
+	tm, dir := startApp(t, notes, nil)
+	tm.Send(tab)
+	waitForScreen(t, tm, "Folders", "Standup")
+
+	// n in the picker starts from the highlighted folder; q is a letter.
+	tm.Send(runes("m"))
+	waitForScreen(t, tm, `Move "Standup" to`)
+	tm.Send(runes("n"))
+	waitForScreen(t, tm, "to a new folder")
+	tm.Type("quarterly")
+	tm.Send(enter)
+
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		n1, _ := s.GetNote("n1")
+		return n1.Folder == "daily/quarterly"
+	})
+	waitForScreen(t, tm, "quarterly")
+}
+
+func TestFirstEditKeepsOriginalInHistory(t *testing.T) {
+	notes := []model.Note{{ID: "n1", Title: "Plan", Folder: "work", Content: "first draft"}}
+	tm, dir := startApp(t, notes, nil)
+	tm.Send(tab)
+	waitForScreen(t, tm, "Plan")
+
+	tm.Send(enter)
+	waitForScreen(t, tm, "Edit Note")
+	tm.Send(tab) // folder
+	tm.Send(tab) // content
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlEnd})
+	tm.Type(", second")
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlS})
+
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		revs, err := s.Revisions("n1")
+		return err == nil && len(revs) == 2 &&
+			revs[0].Content == "first draft, second" && revs[1].Content == "first draft"
+	})
+}
+
+func TestEditMergesChangesFromAnotherInstance(t *testing.T) {
+	notes := []model.Note{{ID: "n1", Title: "Plan", Folder: "work", Content: "first draft"}}
+	tm, dir := startApp(t, notes, nil)
+	tm.Send(tab)
+	waitForScreen(t, tm, "Plan")
+
+	tm.Send(enter)
+	waitForScreen(t, tm, "Edit Note")
+	other, err := storage.NewStorageAt(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.UpsertNote(model.Note{ID: "n1", Title: "Big plan", Folder: "work", Content: "first draft"}); err != nil {
+		t.Fatal(err)
+	}
+	tm.Send(tab) // folder
+	tm.Send(tab) // content
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlEnd})
+	tm.Type(", second")
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlS})
+
+	waitForScreen(t, tm, "Merged with changes")
+	waitForDisk(t, dir, func(s *storage.Storage) bool {
+		n, err := s.GetNote("n1")
+		return err == nil && n.Title == "Big plan" && n.Content == "first draft, second"
+	})
+}
@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mtix28/noteme/model"
+	"github.com/mtix28/noteme/quickadd"
+)
+
+// parseTodoInput turns the text typed into TodoAddView into a new todo and
+// the name of the project it was given, if any; see package quickadd for
+// what it understands.
+func parseTodoInput(text string, now time.Time) (model.Todo, string, error) {
+	res, err := quickadd.Parse(text, now)
+	if err != nil {
+		return model.Todo{}, "", err
+	}
+	if res.Content == "" {
+		return model.Todo{}, "", fmt.Errorf("the todo needs a description")
+	}
+	if !res.Start.IsZero() && !res.Due.IsZero() && res.Due.Before(res.Start) {
+		return model.Todo{}, "", fmt.Errorf("due date is before the start date")
+	}
+	return model.Todo{
+		ID:         uuid.New().String(),
+		Content:    res.Content,
+		Priority:   res.Priority,
+		CreatedAt:  now,
+		Frequency:  res.Frequency,
+		Recurrence: res.Recurrence,
+		Due:        res.Due,
+		Start:      res.Start,
+		Tags:       model.NormalizeTags(res.Tags),
+	}, res.Project, nil
+}
+
+// todoInputText writes t, which belongs to the named project, back as
+// quick-add text for editing.
+func todoInputText(t model.Todo, project string) string {
+	words := []string{t.Content}
+	switch {
+	case t.Recurrence != "":
+		words = append(words, "RRULE:"+t.Recurrence)
+	case t.Frequency.Recurring():
+		words = append(words, "/"+string(t.Frequency))
+	}
+	if !t.Due.IsZero() {
+		due := "due " + t.Due.Format("2006-01-02")
+		if t.HasDueTime() {
+			due += t.Due.Format(" 15:04")
+		}
+		words = append(words, due)
+	}
+	if !t.Start.IsZero() {
+		words = append(words, "start "+t.Start.Format("2006-01-02"))
+	}
+	if t.Priority != model.PriorityNone {
+		words = append(words, "!"+string(t.Priority))
+	}
+	if project != "" {
+		words = append(words, "+"+project)
+	}
+	for _, tag := range t.Tags {
+		words = append(words, "#"+tag)
+	}
+	return strings.Join(words, " ")
+}
+
+// todoPreview shows what the todo input will turn into, updated as the
+// user types.
+func todoPreview(text string, now time.Time) string {
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+	res, err := quickadd.Parse(text, now)
+	if err != nil {
+		return statusStyle.Render(err.Error())
+	}
+	row := func(label, value string) string {
+		return statLabel.Render(fmt.Sprintf("%-10s", label)) + statValue.Render(value)
+	}
+	rows := []string{row("Todo", res.Content)}
+	switch {
+	case res.Recurrence != "":
+		rows = append(rows, row("Repeats", res.Recurrence))
+	case res.Frequency != model.Once:
+		rows = append(rows, row("Repeats", string(res.Frequency)))
+	}
+	if !res.Due.IsZero() {
+		rows = append(rows, row("Due", formatWhen(res.Due)))
+	}
+	if !res.Start.IsZero() {
+		rows = append(rows, row("Starts", formatWhen(res.Start)))
+	}
+	if res.Priority != "" {
+		rows = append(rows, row("Priority", string(res.Priority)))
+	}
+	if len(res.Tags) > 0 {
+		rows = append(rows, row("Tags", tagsLabel(model.NormalizeTags(res.Tags))))
+	}
+	if res.Project != "" {
+		rows = append(rows, row("Project", "+"+model.ProjectName(res.Project)))
+	}
+	return strings.Join(rows, "\n")
+}
+
+func formatWhen(t time.Time) string {
+	if t.Equal(model.Day(t)) {
+		return t.Format("Mon Jan 02 2006")
+	}
+	return t.Format("Mon Jan 02 2006 15:04")
+}
+
+// scheduleLabel describes the due and start dates of an open todo.
+func scheduleLabel(t model.Todo, now time.Time) string {
+	if t.Done {
+		return ""
+	}
+	var parts []string
+	switch {
+	case t.Overdue(now):
+		parts = append(parts, "OVERDUE since "+t.Due.Format("Mon Jan 02"))
+	case !t.Due.IsZero() && t.DueToday(now):
+		parts = append(parts, "due today")
+	case !t.Due.IsZero():
+		parts = append(parts, "due "+t.Due.Format("Mon Jan 02"))
+	}
+	if t.HasDueTime() {
+		parts[len(parts)-1] += t.Due.Format(" 15:04")
+	}
+	if !t.Started(now) {
+		parts = append(parts, "starts "+t.Start.Format("Mon Jan 02"))
+	}
+	return strings.Join(parts, " | ")
+}
@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mtix28/noteme/model"
+	"github.com/mtix28/noteme/storage"
+)
+
+// Trash bin: deleted notes and todos, for backends that keep them. Items can
+// be restored or purged for good from here.
+
+// itemTypeTrashed is the itemToDeleteType used when purging from the trash.
+const itemTypeTrashed = "trashed item"
+
+type trashLoadedMsg struct {
+	items []model.TrashItem
+	err   error
+}
+
+type trashRestoredMsg struct {
+	item model.TrashItem
+	err  error
+}
+
+func (m MainModel) loadTrashCmd() tea.Msg {
+	trash, ok := m.store.(storage.Trash)
+	if !ok {
+		return nil
+	}
+	items, err := trash.TrashedItems()
+	return trashLoadedMsg{items, err}
+}
+
+func (m MainModel) hasTrash() bool {
+	_, ok := m.store.(storage.Trash)
+	return ok
+}
+
+func (m MainModel) updateTrash(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Tab), key.Matches(msg, m.keys.Back):
+		m.state = DashboardView
+		return m, nil
+	case key.Matches(msg, m.keys.Restore):
+		item, ok := m.trashList.SelectedItem().(trashItem)
+		if !ok {
+			return m, nil
+		}
+		trash := m.store.(storage.Trash)
+		return m, func() tea.Msg {
+			return trashRestoredMsg{item.item, trash.RestoreItem(item.item.ID)}
+		}
+	case key.Matches(msg, m.keys.Delete):
+		item, ok := m.trashList.SelectedItem().(trashItem)
+		if !ok {
+			return m, nil
+		}
+		m.itemToDeleteID = item.item.ID
+		m.itemToDeleteType = itemTypeTrashed
+		m.state = DeleteConfirmView
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.trashList, cmd = m.trashList.Update(msg)
+	return m, cmd
+}
+
+func (m *MainModel) updateTrashListItems() {
+	items := make([]list.Item, len(m.trash))
+	for i, t := range m.trash {
+		items[i] = trashItem{t}
+	}
+	m.trashList.SetItems(items)
+}
+
+// trashHelp is the help line of the trash view; r restores the item rather
+// than a note version here.
+func (m MainModel) trashHelp() []key.Binding {
+	restore := m.keys.Restore
+	restore.SetHelp("r", "restore")
+	purge := m.keys.Delete
+	purge.SetHelp("d/x", "delete forever")
+	return []key.Binding{m.keys.Tab, restore, purge, m.keys.Up, m.keys.Down, m.keys.Quit}
+}
+
+type trashItem struct{ item model.TrashItem }
+
+func (t trashItem) FilterValue() string { return t.item.Label() }
+func (t trashItem) Title() string       { return t.item.Label() }
+func (t trashItem) Description() string {
+	return t.item.Kind + " | deleted " + t.item.DeletedAt.Format("2006-01-02 15:04")
+}